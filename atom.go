@@ -15,6 +15,7 @@ package feedparser
 
 import (
 	"encoding/xml"
+	"fmt"
 )
 
 // AtomFeed represents an atom web feed.
@@ -64,6 +65,9 @@ type AtomFeed struct {
 
 // AtomEntry represents an atom entry.
 type AtomEntry struct {
+	// Media RSS and iTunes elements of the entry (optional).
+	Media
+
 	// Universally unique feed ID (required).
 	ID string `xml:"id"`
 
@@ -94,14 +98,53 @@ type AtomEntry struct {
 	// Time of the initial creation of the entry (optional).
 	Published string `xml:"published"`
 
-	// FIXME
 	// Feed's metadata, only used when entry was copied from another feed (optional).
-	// Source AtomFeed `xml:"source"`
+	Source *AtomSource `xml:"source"`
 
 	// Information about rights, for example copyrights (optional).
 	Rights AtomText `xml:"rights"`
 }
 
+// AtomSource represents the metadata of the feed an atom entry was
+// copied from. It contains all elements of AtomFeed except entries.
+type AtomSource struct {
+	// Universally unique feed ID (recommended).
+	ID string `xml:"id"`
+
+	// Human readable title for the feed (recommended).
+	Title AtomText `xml:"title"`
+
+	// Last time the feed was significantly modified (recommended).
+	Updated string `xml:"updated"`
+
+	// Authors of the feed (optional).
+	Authors []AtomPerson `xml:"author"`
+
+	// Links which identify related web pages (optional).
+	Links []AtomLink `xml:"link"`
+
+	// Categories the feed belongs to (optional).
+	Categories []AtomCategory `xml:"category"`
+
+	// Contributors to the feed (optional).
+	Contributors []AtomPerson `xml:"contributor"`
+
+	// Software used to generate the feed (optional).
+	Generator AtomGenerator `xml:"generator"`
+
+	// Small icon used for visual identification (optional).
+	Icon string `xml:"icon"`
+
+	// Larger logo for visual identification (optional).
+	Logo string `xml:"logo"`
+
+	// Information about rights, for example copyrights (optional).
+	Rights AtomText `xml:"rights"`
+
+	// Human readable description or subtitle (optional).
+	Subtitle AtomText `xml:"subtitle"`
+}
+
 // AtomLink represents the atom link tag.
 type AtomLink struct {
 	// Hypertext reference (required).
@@ -164,7 +207,8 @@ type AtomText struct {
 	// Text body (required).
 	Body string `xml:",chardata"`
 
-	// InnerXML data (optional).
+	// InnerXML data (optional). It is not available for items read
+	// using a Decoder.
 	InnerXML string `xml:",innerxml"`
 
 	// Text type (optional).
@@ -175,101 +219,145 @@ type AtomText struct {
 }
 
 // parseAtom parses an atom feed and returns a generic feed.
-func parseAtom(data []byte) (f Feed, err error) {
+func parseAtom(s *parseState, data []byte) (f Feed, err error) {
 	var origFeed AtomFeed
-	if err = unmarshal(data, &origFeed); err != nil {
+	if err = s.unmarshal(data, &origFeed); err != nil {
+		return
+	}
+
+	f, err = atomFeed(s, &origFeed)
+	if err != nil {
 		return
 	}
 
+	for i := range origFeed.Entries {
+		var item Item
+		item, err = atomItem(s, &origFeed.Entries[i], i)
+		if err != nil {
+			return
+		}
+
+		f.Items = append(f.Items, item)
+	}
+
+	return
+}
+
+// atomFeed converts the metadata of an atom feed to a generic feed
+// without items.
+func atomFeed(s *parseState, origFeed *AtomFeed) (f Feed, err error) {
 	f = Feed{
+		ID:          origFeed.ID,
 		Type:        "atom",
 		Title:       origFeed.Title.Body,
-		Link:        findLink(origFeed.Links).Href,
+		Links:       atomLinks(origFeed.Links),
 		Description: origFeed.Subtitle.Body,
-		Image:       origFeed.Logo,
 		Generator:   origFeed.Generator.Name,
 		Rights:      origFeed.Rights.Body,
+		Logo:        FeedImage{URL: origFeed.Logo},
+		Icon:        FeedImage{URL: origFeed.Icon},
 	}
+	f.Link = s.primaryLink(f.Links).Href
+	f.Self = findRel(f.Links, "self").Href
+	feedImages(&f, "")
 
 	if len(origFeed.Authors) > 0 {
 		f.Author = origFeed.Authors[0].Email
 	}
 
-	f.Updated, err = parseTime(origFeed.Updated)
+	f.Updated, err = s.parseTime("/feed/updated", origFeed.Updated)
 	if err != nil {
 		return
 	}
 
+	var categories categories
 	for _, category := range origFeed.Categories {
-		f.Categories = append(f.Categories, category.Term)
+		categories.add(category.Term, category.Scheme, category.Label)
 	}
+	f.Categories, f.CategoryDetails = categories.terms(), categories
 
-	for _, entry := range origFeed.Entries {
-		item := Item{
-			ID:         entry.ID,
-			Title:      entry.Title.Body,
-			Link:       findLink(entry.Links).Href,
-			Content:    entry.Content.Body,
-			Attachment: findAttachment(entry.Links).Href,
-		}
+	return
+}
 
-		if len(entry.Authors) > 0 {
-			item.Author = entry.Authors[0].Email
-		}
+// atomItem converts an atom entry, which is located at the given
+// position in the document, to a generic item.
+func atomItem(s *parseState, entry *AtomEntry, index int) (item Item, err error) {
+	item = Item{
+		ID:      entry.ID,
+		Title:   entry.Title.Body,
+		Links:   atomLinks(entry.Links),
+		Content: entry.Content.Body,
+		Summary: entry.Summary.Body,
+		Index:   index,
+	}
 
-		for _, category := range entry.Categories {
-			item.Categories = append(item.Categories, category.Term)
-		}
+	item.Link = s.primaryLink(item.Links).Href
+	item.Attachment = findRel(item.Links, "enclosure").Href
+
+	if len(entry.Authors) > 0 {
+		item.Author = entry.Authors[0].Email
+	}
 
-		timeStr := entry.Updated
-		if len(entry.Published) > 0 {
-			timeStr = entry.Published
+	var categories categories
+	for _, category := range entry.Categories {
+		categories.add(category.Term, category.Scheme, category.Label)
+	}
+	item.Categories, item.CategoryDetails = categories.terms(), categories
+
+	path := fmt.Sprintf("/feed/entry[%d]", index+1)
+	if len(entry.Updated) > 0 {
+		item.Updated, err = s.parseTime(path+"/updated", entry.Updated)
+		if err != nil {
+			return
 		}
+	}
 
-		item.PubDate, err = parseTime(timeStr)
+	item.PubDate = item.Updated
+	if len(entry.Published) > 0 {
+		item.PubDate, err = s.parseTime(path+"/published", entry.Published)
 		if err != nil {
 			return
 		}
+	}
 
-		f.Items = append(f.Items, item)
+	var enclosures []enclosure
+	for _, link := range item.Links {
+		if link.Rel == "enclosure" {
+			enclosures = append(enclosures, enclosure{link.Href, link.Type})
+		}
 	}
 
-	return
-}
+	content := item.Content
+	if len(content) == 0 {
+		content = item.Summary
+	}
+	item.Image = findImage(item.Link, &entry.Media, enclosures, content)
 
-// findLink attempts to find the most relevant link.
-func findLink(links []AtomLink) AtomLink {
-	var score int
-	var match AtomLink
-
-	for _, link := range links {
-		switch {
-		case link.Rel == "alternate" && link.Type == "text/html":
-			return link
-		case score < 3 && link.Type == "text/html":
-			score = 3
-			match = link
-		case score < 2 && link.Rel == "self":
-			score = 2
-			match = link
-		case score < 1 && link.Rel == "":
-			score = 1
-			match = link
-		case &match == nil:
-			match = link
-		}
+	if entry.Source != nil {
+		item.Source, err = atomSource(s, path+"/source", entry.Source)
 	}
 
-	return match
+	return
 }
 
-// findAttachment attempts to find a link which represents an attachment.
-func findAttachment(links []AtomLink) AtomLink {
-	for _, link := range links {
-		if link.Rel == "enclosure" {
-			return link
+// atomSource converts the source of an atom entry, which is located at
+// the given path in the document, to a generic source.
+func atomSource(s *parseState, path string, source *AtomSource) (*Source, error) {
+	links := atomLinks(source.Links)
+	src := &Source{
+		ID:    source.ID,
+		Title: source.Title.Body,
+		Link:  s.primaryLink(links).Href,
+		Self:  findRel(links, "self").Href,
+	}
+
+	if len(source.Updated) > 0 {
+		updated, err := s.parseTime(path+"/updated", source.Updated)
+		if err != nil {
+			return nil, err
 		}
+		src.Updated = updated
 	}
 
-	return AtomLink{}
+	return src, nil
 }
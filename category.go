@@ -0,0 +1,73 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"strings"
+)
+
+// ItunesScheme is the scheme of categories taken from iTunes categories.
+const ItunesScheme = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+
+// Category represents a category of a feed or an item.
+type Category struct {
+	// Identifier of the category. Nested iTunes categories use the
+	// terms of their ancestors as prefix, e.g. Technology/Podcasting.
+	Term string
+
+	// URI identifying the categorization scheme or rss domain (optional).
+	Scheme string
+
+	// Human readable label for display (optional).
+	Label string
+}
+
+// ItunesCategory represents the iTunes category tag.
+type ItunesCategory struct {
+	// Name of the category (required).
+	Text string `xml:"text,attr"`
+
+	// Subcategories of the category (optional).
+	Categories []ItunesCategory `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd category"`
+}
+
+// categories collects the categories of a feed or an item.
+type categories []Category
+
+// add adds a category with the given term, empty terms are ignored.
+func (c *categories) add(term, scheme, label string) {
+	term = strings.TrimSpace(term)
+	if len(term) > 0 {
+		*c = append(*c, Category{term, strings.TrimSpace(scheme), strings.TrimSpace(label)})
+	}
+}
+
+// addItunes adds the given iTunes categories including their
+// subcategories.
+func (c *categories) addItunes(prefix string, itunes []ItunesCategory) {
+	for _, category := range itunes {
+		term := prefix + strings.TrimSpace(category.Text)
+		c.add(term, ItunesScheme, category.Text)
+		c.addItunes(term+"/", category.Categories)
+	}
+}
+
+// terms returns the terms of all categories.
+func (c categories) terms() (terms []string) {
+	for _, category := range c {
+		terms = append(terms, category.Term)
+	}
+
+	return
+}
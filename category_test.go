@@ -0,0 +1,87 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCategories(t *testing.T) {
+	data := `<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/"
+		xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+		<category domain="http://example.org/tags">go</category>
+		<itunes:category text="Technology">
+			<itunes:category text="Podcasting"/>
+		</itunes:category>
+		<itunes:category text="Music"/>
+		<dc:subject>Programming</dc:subject>
+		<item>
+			<pubDate>Wed, 05 Aug 2015 10:00:00 GMT</pubDate>
+			<category> xml </category>
+			<category></category>
+			<dc:subject>Parsing</dc:subject>
+		</item>
+	</channel></rss>`
+
+	feed, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Category{
+		{"go", "http://example.org/tags", ""},
+		{"Programming", "", ""},
+		{"Technology", ItunesScheme, "Technology"},
+		{"Technology/Podcasting", ItunesScheme, "Podcasting"},
+		{"Music", ItunesScheme, "Music"},
+	}
+	if !reflect.DeepEqual(feed.CategoryDetails, expected) {
+		t.Fatalf("Expected %v - got %v", expected, feed.CategoryDetails)
+	}
+
+	terms := []string{"go", "Programming", "Technology", "Technology/Podcasting", "Music"}
+	if !reflect.DeepEqual(feed.Categories, terms) {
+		t.Fatalf("Expected %q - got %q", terms, feed.Categories)
+	}
+
+	expected = []Category{{"xml", "", ""}, {"Parsing", "", ""}}
+	if !reflect.DeepEqual(feed.Items[0].CategoryDetails, expected) {
+		t.Fatalf("Expected %v - got %v", expected, feed.Items[0].CategoryDetails)
+	}
+
+	data = `<feed xmlns="http://www.w3.org/2005/Atom">
+		<updated>2015-08-05T18:30:02Z</updated>
+		<category term="go" scheme="http://example.org/tags" label="Go"/>
+		<entry>
+			<updated>2015-08-05T18:30:02Z</updated>
+			<category term="xml"/>
+		</entry>
+	</feed>`
+
+	feed, err = Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []Category{{"go", "http://example.org/tags", "Go"}}
+	if !reflect.DeepEqual(feed.CategoryDetails, expected) {
+		t.Fatalf("Expected %v - got %v", expected, feed.CategoryDetails)
+	}
+
+	if !reflect.DeepEqual(feed.Items[0].Categories, []string{"xml"}) {
+		t.Fatalf("Expected %q - got %q", []string{"xml"}, feed.Items[0].Categories)
+	}
+}
@@ -0,0 +1,216 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// fallbackCharset is the charset assumed for documents which are
+// neither valid UTF-8 nor declare their encoding.
+const fallbackCharset = "windows-1252"
+
+// declRegex matches the encoding declared in the XML declaration.
+var declRegex = regexp.MustCompile(`^\s*<\?xml[^>]*encoding=["']([^"']+)["']`)
+
+// boms lists byte order marks and the encodings they identify.
+var boms = []struct {
+	bom   []byte
+	label string
+}{
+	{[]byte{0xef, 0xbb, 0xbf}, "utf-8"},
+	{[]byte{0xfe, 0xff}, "utf-16be"},
+	{[]byte{0xff, 0xfe}, "utf-16le"},
+}
+
+// lookupCharset returns the encoding for the given charset label.
+func lookupCharset(label string) (encoding.Encoding, error) {
+	e, _ := charset.Lookup(label)
+	if e == nil {
+		return nil, fmt.Errorf("feedparser: unsupported charset %q", label)
+	}
+
+	return e, nil
+}
+
+// findBOM returns the charset identified by the byte order mark the
+// given data starts with and the length of the byte order mark.
+func findBOM(data []byte) (string, int) {
+	for _, b := range boms {
+		if bytes.HasPrefix(data, b.bom) {
+			return b.label, len(b.bom)
+		}
+	}
+
+	return "", 0
+}
+
+// contentTypeCharset returns the charset parameter of the given HTTP
+// Content-Type header.
+func contentTypeCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	return params["charset"]
+}
+
+// toUTF8 converts the document to UTF-8 if its encoding is forced by
+// the parser, identified by a byte order mark or the given HTTP
+// Content-Type header or if the document is invalid UTF-8 and doesn't
+// declare a different encoding. Otherwise, the encoding declared in
+// the XML declaration is used while decoding. Declared single-byte
+// encodings are ignored for documents which are valid UTF-8, see
+// headerCharset for the handling of the Content-Type header.
+func (s *parseState) toUTF8(data []byte, contentType string) ([]byte, error) {
+	label, n := findBOM(data)
+	switch {
+	case len(s.parser.Charset) > 0:
+		label = s.parser.Charset
+	case n > 0:
+		data = data[n:]
+	default:
+		label = s.headerCharset(contentType, data)
+		if len(label) > 0 {
+			if s.mislabeled(label, data) {
+				return data, nil
+			}
+			break
+		}
+
+		match := declRegex.FindSubmatch(data)
+		if match != nil && !isUTF8Label(string(match[1])) {
+			s.mislabeled(string(match[1]), data)
+			return data, nil
+		} else if utf8.Valid(data) {
+			s.utf8 = true
+			return data, nil
+		}
+
+		label = fallbackCharset
+		s.warn("document is not valid UTF-8, assuming %s", label)
+	}
+
+	e, err := lookupCharset(label)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = e.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s.utf8 = true
+	return data, nil
+}
+
+// headerCharset returns the charset of the given HTTP Content-Type
+// header. Unsupported charsets and a UTF-8 charset for a document which
+// isn't valid UTF-8 are ignored with a warning, the encoding is then
+// detected from the document instead.
+func (s *parseState) headerCharset(contentType string, data []byte) string {
+	label := contentTypeCharset(contentType)
+	if len(label) == 0 {
+		return ""
+	}
+
+	if _, err := lookupCharset(label); err != nil {
+		s.warn("ignoring unsupported charset %q of Content-Type header", label)
+		return ""
+	} else if isUTF8Label(label) && !utf8.Valid(data) {
+		s.warn("ignoring charset %q of Content-Type header, document is not valid UTF-8", label)
+		return ""
+	}
+
+	return label
+}
+
+// mislabeled reports whether a document declaring the given single-byte
+// charset is actually UTF-8, in which case it is decoded as such. This
+// is assumed if the document contains non-ASCII characters and is valid
+// UTF-8 since text in a single-byte charset is very unlikely to be.
+func (s *parseState) mislabeled(label string, data []byte) bool {
+	e, _ := htmlindex.Get(strings.TrimSpace(label))
+	if _, ok := e.(*charmap.Charmap); !ok || !utf8.Valid(data) {
+		return false
+	}
+
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			s.utf8 = true
+			s.warn("document declares charset %s but is UTF-8", label)
+			return true
+		}
+	}
+
+	return false
+}
+
+// utf8Reader is like toUTF8 but operates on a stream, the encoding is
+// only detected by the configuration of the parser or a byte order
+// mark.
+func (s *parseState) utf8Reader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	prefix, _ := br.Peek(3)
+
+	label, n := findBOM(prefix)
+	if len(s.parser.Charset) > 0 {
+		label = s.parser.Charset
+	} else if n > 0 {
+		br.Discard(n)
+	} else {
+		return br, nil
+	}
+
+	e, err := lookupCharset(label)
+	if err != nil {
+		return nil, err
+	}
+
+	s.utf8 = true
+	return transform.NewReader(br, e.NewDecoder()), nil
+}
+
+// charsetReader returns the CharsetReader used by the XML decoder. If
+// the document was already converted to UTF-8 the declared encoding is
+// ignored.
+func (s *parseState) charsetReader() func(string, io.Reader) (io.Reader, error) {
+	if !s.utf8 {
+		return charset.NewReaderLabel
+	}
+
+	return func(label string, r io.Reader) (io.Reader, error) {
+		return r, nil
+	}
+}
+
+// isUTF8Label reports whether the given charset label refers to UTF-8.
+func isUTF8Label(label string) bool {
+	label = strings.ToLower(strings.TrimSpace(label))
+	return label == "utf-8" || label == "utf8"
+}
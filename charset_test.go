@@ -0,0 +1,74 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"unicode/utf16"
+)
+
+type charsetpair struct {
+	Data        []byte
+	ContentType string
+	Charset     string
+	Title       string
+}
+
+func encodeUTF16(s string) []byte {
+	buf := []byte{0xff, 0xfe}
+	for _, c := range utf16.Encode([]rune(s)) {
+		buf = append(buf, byte(c), byte(c>>8))
+	}
+
+	return buf
+}
+
+func TestCharset(t *testing.T) {
+	doc := func(decl, title string) []byte {
+		return []byte(decl + "<rss><channel><title>" + title + "</title></channel></rss>")
+	}
+
+	tests := []charsetpair{
+		{doc("", "Caf\xc3\xa9"), "", "", "Café"},
+		{doc("\xef\xbb\xbf", "Caf\xc3\xa9"), "", "", "Café"},
+		{encodeUTF16(`<?xml version="1.0" encoding="utf-16"?><rss><channel><title>Café</title></channel></rss>`), "", "", "Café"},
+		{doc(`<?xml version="1.0" encoding="iso-8859-1"?>`, "Caf\xe9"), "", "", "Café"},
+		{doc("", "Caf\xe9 \x80"), "", "", "Café €"},
+		{doc(`<?xml version="1.0" encoding="utf-8"?>`, "Caf\xe9"), "", "", "Café"},
+		{doc(`<?xml version="1.0" encoding="iso-8859-1"?>`, "Caf\xc3\xa9"), "", "", "Café"},
+		{doc("", "Caf\xc3\xa9"), "text/xml; charset=windows-1252", "", "Café"},
+		{doc(`<?xml version="1.0" encoding="iso-8859-1"?>`, "Caf\xc3\xa9"), "", "iso-8859-1", "CafÃ©"},
+		{doc("", "Caf\xe9"), "text/xml; charset=iso-8859-15", "", "Café"},
+		{doc(`<?xml version="1.0" encoding="utf-8"?>`, "\xa4"), "application/rss+xml; charset=ISO-8859-15", "", "€"},
+		{doc(`<?xml version="1.0" encoding="utf-8"?>`, "\xa4"), "text/xml; charset=utf-8", "iso-8859-15", "€"},
+		{doc("", "Caf\xe9"), "text/xml; charset=utf-8", "", "Café"},
+		{doc(`<?xml version="1.0" encoding="iso-8859-15"?>`, "\xa4"), "text/xml; charset=UTF-8", "", "€"},
+		{doc("", "Caf\xc3\xa9"), "text/xml; charset=bogus", "", "Café"},
+		{doc(`<?xml version="1.0" encoding="iso-8859-15"?>`, "\xa4"), "text/xml; charset=bogus", "", "€"},
+	}
+
+	for _, test := range tests {
+		parser := &Parser{Charset: test.Charset}
+		feed, err := parser.ParseContentType(context.Background(), bytes.NewReader(test.Data), test.ContentType)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if feed.Title != test.Title {
+			t.Fatalf("Expected %q - got %q", test.Title, feed.Title)
+		}
+	}
+}
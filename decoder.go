@@ -0,0 +1,245 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// Decoder reads the items of a feed from an input stream one at a time
+// instead of buffering the entire document. Metadata which appears
+// after the first item is ignored and the items are returned in
+// document order. If the parser is lenient, invalid characters are
+// removed and the XML is decoded in non-strict mode but no further
+// repairs are attempted.
+type Decoder struct {
+	s *parseState
+	d *xml.Decoder
+
+	// Parsed feed metadata and error encountered while parsing it.
+	header    Feed
+	headerErr error
+	started   bool
+
+	// Names of all currently open elements.
+	stack []xml.Name
+
+	// Start element of the next item, if already read.
+	next *xml.StartElement
+
+	// Position of the next item in the document.
+	index int
+}
+
+// NewDecoder returns a new Decoder reading from the given reader using
+// the DefaultParser.
+func NewDecoder(r io.Reader) *Decoder {
+	return DefaultParser.NewDecoder(r)
+}
+
+// NewDecoder returns a new Decoder reading from the given reader using
+// the configuration of the parser.
+func (p *Parser) NewDecoder(r io.Reader) *Decoder {
+	return p.NewDecoderContext(context.Background(), r)
+}
+
+// NewDecoderContext is like NewDecoder but the returned Decoder fails
+// once the given context is done. Apart from the Charset of the parser,
+// only byte order marks and the encoding declared in the XML
+// declaration are considered for decoding the stream.
+func (p *Parser) NewDecoderContext(ctx context.Context, r io.Reader) *Decoder {
+	s := &parseState{ctx: ctx, parser: p, lenient: p.Lenient}
+
+	r, err := s.utf8Reader(s.reader(r))
+	if err != nil {
+		return &Decoder{s: s, started: true, headerErr: err}
+	}
+
+	if s.lenient {
+		r = &validReader{r: r}
+	}
+
+	return &Decoder{s: s, d: xml.NewTokenDecoder(s.limit(s.newDecoder(r), true))}
+}
+
+// Header returns the metadata of the feed. The Items field of the
+// returned feed is always empty.
+func (d *Decoder) Header() (Feed, error) {
+	if !d.started {
+		d.started = true
+		d.header, d.headerErr = d.readHeader()
+		d.headerErr = d.s.wrap(d.headerErr)
+	}
+
+	return d.header, d.headerErr
+}
+
+// Next returns the next item of the feed. At the end of the feed Next
+// returns io.EOF.
+func (d *Decoder) Next() (item Item, err error) {
+	if _, err = d.Header(); err != nil {
+		return
+	}
+
+	// Only retain positions of the current item.
+	d.s.positions = make(map[string]Position)
+
+	start, err := d.nextItem()
+	if err == io.EOF {
+		return
+	} else if err != nil {
+		err = d.s.wrap(err)
+		return
+	}
+
+	switch d.header.Type {
+	case "atom":
+		var entry AtomEntry
+		if err = d.d.DecodeElement(&entry, start); err != nil {
+			err = d.s.wrap(err)
+			return
+		}
+		item, err = atomItem(d.s, &entry, d.index)
+	case "rss":
+		var entry RssItem
+		if err = d.d.DecodeElement(&entry, start); err != nil {
+			err = d.s.wrap(err)
+			return
+		}
+		item, err = rssItem(d.s, &entry, d.index)
+	}
+
+	d.index++
+	return
+}
+
+// readHeader reads all tokens up to the first item and decodes them as
+// feed metadata.
+func (d *Decoder) readHeader() (f Feed, err error) {
+	var root xml.Name
+	var tokens []xml.Token
+
+Loop:
+	for {
+		var tok xml.Token
+		tok, err = d.d.Token()
+		if err == io.EOF {
+			err = errors.New("feedparser: document contains no elements")
+			return
+		} else if err != nil {
+			return
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if isItem(d.parent(), t.Name.Local) {
+				d.next = &t
+				break Loop
+			} else if len(d.stack) == 0 {
+				root = t.Name
+			}
+			d.stack = append(d.stack, t.Name)
+		case xml.EndElement:
+			d.stack = d.stack[:len(d.stack)-1]
+		}
+
+		tokens = append(tokens, xml.CopyToken(tok))
+		if len(d.stack) == 0 && len(root.Local) > 0 {
+			break
+		}
+	}
+
+	// Close all elements which are still open to obtain a complete
+	// document containing only the metadata.
+	for i := len(d.stack) - 1; i >= 0; i-- {
+		tokens = append(tokens, xml.EndElement{Name: d.stack[i]})
+	}
+	r := xml.NewTokenDecoder(&tokenSlice{tokens})
+
+	switch root.Local {
+	case "feed":
+		var origFeed AtomFeed
+		if err = r.Decode(&origFeed); err != nil {
+			return
+		}
+		f, err = atomFeed(d.s, &origFeed)
+	case "rss":
+		var origFeed RssFeed
+		if err = r.Decode(&origFeed); err != nil {
+			return
+		}
+		f, err = rssFeed(d.s, &origFeed)
+	default:
+		err = unknownFormat(root)
+	}
+
+	return
+}
+
+// nextItem returns the start element of the next item, skipping all
+// other elements.
+func (d *Decoder) nextItem() (*xml.StartElement, error) {
+	if d.next != nil {
+		start := d.next
+		d.next = nil
+		return start, nil
+	}
+
+	for len(d.stack) > 0 {
+		tok, err := d.d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if isItem(d.parent(), t.Name.Local) {
+				return &t, nil
+			} else if err := d.d.Skip(); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			d.stack = d.stack[:len(d.stack)-1]
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// parent returns the local name of the innermost open element.
+func (d *Decoder) parent() string {
+	if len(d.stack) == 0 {
+		return ""
+	}
+
+	return d.stack[len(d.stack)-1].Local
+}
+
+// tokenSlice is an xml.TokenReader which returns tokens from a slice.
+type tokenSlice struct {
+	tokens []xml.Token
+}
+
+func (t *tokenSlice) Token() (xml.Token, error) {
+	if len(t.tokens) == 0 {
+		return nil, io.EOF
+	}
+
+	tok := t.tokens[0]
+	t.tokens = t.tokens[1:]
+	return tok, nil
+}
@@ -0,0 +1,116 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+const testAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Example Feed</title>
+	<link href="http://example.org/"/>
+	<updated>2015-08-05T18:30:02Z</updated>
+	<author><name>John Doe</name></author>
+	<id>urn:uuid:60a76c80-d399-11d9-b93c-0003939e0af6</id>
+	<entry>
+		<title>First</title>
+		<link href="http://example.org/2015/08/03/first"/>
+		<id>1</id>
+		<updated>2015-08-03T18:30:02Z</updated>
+	</entry>
+	<entry>
+		<title>Second</title>
+		<link href="http://example.org/2015/08/05/second"/>
+		<id>2</id>
+		<updated>2015-08-05T18:30:02Z</updated>
+	</entry>
+</feed>`
+
+type decoderpair struct {
+	Data  string
+	Type  string
+	Title string
+	IDs   string
+}
+
+func TestDecoder(t *testing.T) {
+	tests := []decoderpair{
+		{testAtom, "atom", "Example Feed", "12"},
+		{testRss, "rss", "Test", "123"},
+	}
+
+	for _, test := range tests {
+		decoder := NewDecoder(strings.NewReader(test.Data))
+		feed, err := decoder.Header()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if feed.Type != test.Type {
+			t.Fatalf("Expected %q - got %q", test.Type, feed.Type)
+		}
+		if feed.Title != test.Title {
+			t.Fatalf("Expected %q - got %q", test.Title, feed.Title)
+		}
+
+		var ids string
+		for {
+			item, err := decoder.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			ids += item.ID
+		}
+
+		if ids != test.IDs {
+			t.Fatalf("Expected %q - got %q", test.IDs, ids)
+		}
+	}
+}
+
+func TestDecoderLenient(t *testing.T) {
+	data := `<rss><channel><title>A & B</title><link>http://example.org/</link>
+		<item><guid>1</guid><link>http://example.org/1</link><pubDate>Wed, 05 Aug 2015 10:00:00 GMT</pubDate></item>
+		<item><guid>2</guid><link>http://example.org/2</link><pubDate>Thu, 06 Aug 2015 10:00:00 GMT</pubDate></item>
+	</channel></rss>`
+
+	decoder := (&Parser{Lenient: true}).NewDecoder(strings.NewReader(data))
+	feed, err := decoder.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Link != "http://example.org/" {
+		t.Fatalf("Expected %q - got %q", "http://example.org/", feed.Link)
+	}
+
+	var links []string
+	for {
+		item, err := decoder.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		links = append(links, item.Link)
+	}
+
+	if len(links) != 2 || links[1] != "http://example.org/2" {
+		t.Fatalf("Expected two items with links - got %q", links)
+	}
+}
@@ -0,0 +1,188 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// field describes a field compared by Diff.
+type field struct {
+	name  string
+	value func(f *Feed, i *Item) string
+}
+
+// feedFields lists the metadata fields of a feed compared by Diff.
+var feedFields = []field{
+	{"ID", func(f *Feed, _ *Item) string { return f.ID }},
+	{"Title", func(f *Feed, _ *Item) string { return f.Title }},
+	{"Link", func(f *Feed, _ *Item) string { return f.Link }},
+	{"Self", func(f *Feed, _ *Item) string { return f.Self }},
+	{"Description", func(f *Feed, _ *Item) string { return f.Description }},
+	{"Categories", func(f *Feed, _ *Item) string { return strings.Join(f.Categories, ", ") }},
+	{"Author", func(f *Feed, _ *Item) string { return f.Author }},
+	{"Updated", func(f *Feed, _ *Item) string { return formatTime(f.Updated) }},
+	{"Logo", func(f *Feed, _ *Item) string { return f.Logo.URL }},
+	{"Icon", func(f *Feed, _ *Item) string { return f.Icon.URL }},
+	{"Generator", func(f *Feed, _ *Item) string { return f.Generator }},
+	{"Rights", func(f *Feed, _ *Item) string { return f.Rights }},
+}
+
+// itemFields lists the fields of an item compared by Diff.
+var itemFields = []field{
+	{"Title", func(_ *Feed, i *Item) string { return i.Title }},
+	{"Link", func(_ *Feed, i *Item) string { return i.Link }},
+	{"Content", func(_ *Feed, i *Item) string { return i.Content }},
+	{"Summary", func(_ *Feed, i *Item) string { return i.Summary }},
+	{"Attachment", func(_ *Feed, i *Item) string { return i.Attachment }},
+	{"Enclosures", func(_ *Feed, i *Item) string { return enclosures(i) }},
+	{"Image", func(_ *Feed, i *Item) string { return i.Image }},
+	{"PubDate", func(_ *Feed, i *Item) string { return formatTime(i.PubDate) }},
+	{"Updated", func(_ *Feed, i *Item) string { return formatTime(i.Updated) }},
+}
+
+// Changes describes the differences between two versions of a feed.
+type Changes struct {
+	// Changed metadata fields of the feed.
+	Feed []FieldChange
+
+	// Items only present in the new feed, in the order of the new feed.
+	Added []Item
+
+	// Items only present in the old feed, in the order of the old feed.
+	Removed []Item
+
+	// Items present in both feeds which were modified, in the order of
+	// the new feed.
+	Modified []ItemChange
+}
+
+// Empty reports whether there are no changes.
+func (c Changes) Empty() bool {
+	return len(c.Feed) == 0 && len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// FieldChange describes a changed field, dates are formatted according
+// to RFC 3339 and empty if not set.
+type FieldChange struct {
+	// Name of the field, e.g. Title.
+	Field string
+
+	// Old value of the field.
+	Old string
+
+	// New value of the field.
+	New string
+}
+
+// ItemChange describes a modified item.
+type ItemChange struct {
+	// Key of the item, see Item.Key.
+	Key string
+
+	// Old version of the item.
+	Old Item
+
+	// New version of the item.
+	New Item
+
+	// Changed fields of the item.
+	Fields []FieldChange
+}
+
+// Diff returns the changes between the old and the new version of a
+// feed. Items are matched using their key, if multiple items share the
+// same key only the first one is considered. Since only exported fields
+// are compared, feeds which were persisted as JSON can be diffed.
+func Diff(old, new Feed) Changes {
+	var changes Changes
+	changes.Feed = diffFields(feedFields, &old, nil, &new, nil)
+
+	oldItems := make(map[string]*Item)
+	for i := range old.Items {
+		key := old.Items[i].Key()
+		if _, ok := oldItems[key]; !ok {
+			oldItems[key] = &old.Items[i]
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := range new.Items {
+		item := &new.Items[i]
+
+		key := item.Key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		oldItem, ok := oldItems[key]
+		if !ok {
+			changes.Added = append(changes.Added, *item)
+			continue
+		}
+
+		fields := diffFields(itemFields, nil, oldItem, nil, item)
+		if len(fields) > 0 {
+			changes.Modified = append(changes.Modified, ItemChange{key, *oldItem, *item, fields})
+		}
+	}
+
+	for i := range old.Items {
+		key := old.Items[i].Key()
+		if !seen[key] && oldItems[key] == &old.Items[i] {
+			changes.Removed = append(changes.Removed, old.Items[i])
+		}
+	}
+
+	return changes
+}
+
+// diffFields returns the given fields which differ between the old and
+// the new feed or item.
+func diffFields(fields []field, oldFeed *Feed, oldItem *Item, newFeed *Feed, newItem *Item) (changes []FieldChange) {
+	for _, f := range fields {
+		o, n := f.value(oldFeed, oldItem), f.value(newFeed, newItem)
+		if o != n {
+			changes = append(changes, FieldChange{f.name, o, n})
+		}
+	}
+
+	return
+}
+
+// formatTime formats the given time according to RFC 3339 in UTC, the
+// zero time is formatted as an empty string.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// enclosures formats the enclosure links of the given item including
+// their media type and length, one enclosure per line.
+func enclosures(i *Item) string {
+	var lines []string
+	for _, link := range i.Links {
+		if link.Rel == "enclosure" {
+			lines = append(lines, fmt.Sprintf("%s (%s, %d bytes)", link.Href, link.Type, link.Length))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,84 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old, err := Parse(strings.NewReader(testRss))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var persisted Feed
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		t.Fatal(err)
+	}
+
+	if changes := Diff(persisted, old); !changes.Empty() {
+		t.Fatalf("Expected no changes - got %+v", changes)
+	}
+
+	new := old
+	new.Title = "New"
+	new.Items = []Item{old.Items[0], old.Items[1], {ID: "4", Title: "Fourth"}}
+	new.Items[0].Content = "Updated"
+	new.Items[0].Updated = time.Date(2015, time.August, 6, 10, 0, 0, 0, time.UTC)
+	new.Items[1].Links = []Link{
+		{Href: "http://example.org/3.mp3", Rel: "enclosure", Type: "audio/mpeg", Length: 42},
+		{Href: "http://example.org/3.ogg", Rel: "enclosure", Type: "audio/ogg"},
+	}
+
+	changes := Diff(persisted, new)
+
+	expected := []FieldChange{{"Title", "Test", "New"}}
+	if !reflect.DeepEqual(changes.Feed, expected) {
+		t.Fatalf("Expected %v - got %v", expected, changes.Feed)
+	}
+
+	if len(changes.Added) != 1 || changes.Added[0].ID != "4" {
+		t.Fatalf("Expected item 4 to be added - got %v", changes.Added)
+	}
+	if len(changes.Removed) != 1 || changes.Removed[0].ID != "1" {
+		t.Fatalf("Expected item 1 to be removed - got %v", changes.Removed)
+	}
+
+	expected = []FieldChange{
+		{"Content", "", "Updated"},
+		{"Updated", "2015-08-05T10:00:00Z", "2015-08-06T10:00:00Z"},
+	}
+	if len(changes.Modified) != 2 || changes.Modified[0].Key != "2" {
+		t.Fatalf("Expected items 2 and 3 to be modified - got %v", changes.Modified)
+	}
+	if fields := changes.Modified[0].Fields; !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("Expected %v - got %v", expected, fields)
+	}
+
+	expected = []FieldChange{{"Enclosures", "",
+		"http://example.org/3.mp3 (audio/mpeg, 42 bytes)\nhttp://example.org/3.ogg (audio/ogg, 0 bytes)"}}
+	if fields := changes.Modified[1].Fields; changes.Modified[1].Key != "3" || !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("Expected %v - got %v", expected, fields)
+	}
+}
@@ -0,0 +1,256 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"context"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// feedTypes maps MIME types of feeds to feed types.
+var feedTypes = map[string]string{
+	"application/rss+xml":   "rss",
+	"application/atom+xml":  "atom",
+	"application/feed+json": "json",
+}
+
+// feedSuffixes maps common suffixes of feed URL paths to feed types, an
+// empty type means the type is unknown.
+var feedSuffixes = []struct {
+	suffix string
+	typ    string
+}{
+	{"/feed", ""},
+	{"/feed/", ""},
+	{"/rss", "rss"},
+	{"/rss/", "rss"},
+	{"/rss.xml", "rss"},
+	{"/atom", "atom"},
+	{"/atom.xml", "atom"},
+	{"/feed.xml", ""},
+	{"/index.xml", ""},
+	{".rss", "rss"},
+	{".atom", "atom"},
+}
+
+// FeedLink represents a link to a feed found on an HTML page.
+type FeedLink struct {
+	// Absolute URL of the feed.
+	URL string
+
+	// Human readable title of the link (optional).
+	Title string
+
+	// Feed type (either atom, rss, json or empty if unknown).
+	Type string
+}
+
+// Discover finds links to feeds on the HTML page read from the given
+// reader, relative links are resolved against the given page URL. If
+// the page URL is nil, relative links are ignored unless the page has a
+// base element with an absolute URL. Feeds
+// advertised using link elements are returned first, followed by anchors
+// which look like links to feeds. The returned links are unique.
+func Discover(r io.Reader, pageURL *url.URL) ([]FeedLink, error) {
+	r, err := charset.NewReader(r, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var links, anchors []FeedLink
+	seen := make(map[string]bool)
+
+	base := pageURL
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				return nil, z.Err()
+			}
+			return append(links, anchors...), nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+		default:
+			continue
+		}
+
+		tok := z.Token()
+		switch tok.DataAtom {
+		case atom.Base:
+			if u, err := parseRef(base, attr(tok, "href")); err == nil && u.IsAbs() {
+				base = u
+			}
+		case atom.Link:
+			typ, ok := linkType(tok)
+			if !ok {
+				continue
+			}
+
+			link, ok := newFeedLink(base, attr(tok, "href"), attr(tok, "title"), typ, seen)
+			if ok {
+				links = append(links, link)
+			}
+		case atom.A:
+			typ, ok := anchorType(attr(tok, "href"))
+			if !ok {
+				continue
+			}
+
+			link, ok := newFeedLink(base, attr(tok, "href"), anchorTitle(z, tok), typ, seen)
+			if ok {
+				anchors = append(anchors, link)
+			}
+		}
+	}
+}
+
+// FetchDiscover is like Fetch but if the document at the given URL is
+// not a feed, the feeds linked from it are discovered and the first one
+// which can be fetched and parsed is returned instead. The URL field of
+// the result contains the URL of the discovered feed.
+func (f *Fetcher) FetchDiscover(ctx context.Context, url string, prev *FetchState) (*Result, error) {
+	return f.fetch(ctx, url, prev, true)
+}
+
+// discover fetches the first feed linked from the given page which can
+// be parsed. If there is none, the given parse error is returned.
+func (f *Fetcher) discover(ctx context.Context, page io.Reader, pageURL *url.URL, parseErr error) (*Result, error) {
+	links, err := Discover(page, pageURL)
+	if err != nil {
+		return nil, parseErr
+	}
+
+	for _, link := range links {
+		if link.Type == "json" {
+			continue
+		}
+
+		result, err := f.Fetch(ctx, link.URL, nil)
+		if err == nil {
+			return result, nil
+		} else if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
+	return nil, parseErr
+}
+
+// newFeedLink creates a new feed link, resolving the reference against
+// the given base URL. It reports false if the reference is invalid or
+// was already seen.
+func newFeedLink(base *url.URL, ref, title, typ string, seen map[string]bool) (FeedLink, bool) {
+	if len(ref) == 0 {
+		return FeedLink{}, false
+	}
+
+	u, err := parseRef(base, strings.TrimSpace(ref))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return FeedLink{}, false
+	}
+	u.Fragment = ""
+
+	if seen[u.String()] {
+		return FeedLink{}, false
+	}
+	seen[u.String()] = true
+
+	return FeedLink{u.String(), strings.TrimSpace(title), typ}, true
+}
+
+// parseRef parses the given reference relative to the given base URL,
+// which may be nil.
+func parseRef(base *url.URL, ref string) (*url.URL, error) {
+	if base == nil {
+		return url.Parse(ref)
+	}
+
+	return base.Parse(ref)
+}
+
+// linkType returns the feed type of a link element and reports whether
+// the link element refers to a feed.
+func linkType(tok html.Token) (string, bool) {
+	var alternate, feed bool
+	for _, rel := range strings.Fields(strings.ToLower(attr(tok, "rel"))) {
+		alternate = alternate || rel == "alternate"
+		feed = feed || rel == "feed"
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(attr(tok, "type"))
+	typ, ok := feedTypes[mediaType]
+	if alternate && ok {
+		return typ, true
+	}
+
+	return typ, feed
+}
+
+// anchorType returns the feed type of an anchor and reports whether the
+// anchor looks like it refers to a feed.
+func anchorType(href string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", false
+	}
+
+	path := strings.ToLower(u.Path)
+	for _, s := range feedSuffixes {
+		if strings.HasSuffix(path, s.suffix) {
+			return s.typ, true
+		}
+	}
+
+	return "", false
+}
+
+// anchorTitle returns the title of an anchor, which is either the value
+// of its title attribute or the text it contains.
+func anchorTitle(z *html.Tokenizer, tok html.Token) string {
+	if title := attr(tok, "title"); len(title) > 0 || tok.Type == html.SelfClosingTagToken {
+		return title
+	}
+
+	var text strings.Builder
+	for {
+		switch z.Next() {
+		case html.TextToken:
+			text.Write(z.Text())
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "a" {
+				return strings.Join(strings.Fields(text.String()), " ")
+			}
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(text.String()), " ")
+		}
+	}
+}
+
+// attr returns the value of the attribute with the given name.
+func attr(tok html.Token, name string) string {
+	for _, a := range tok.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+
+	return ""
+}
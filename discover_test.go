@@ -0,0 +1,120 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Example</title>
+	<link rel="stylesheet" href="/style.css">
+	<link rel="alternate" type="application/rss+xml" title="RSS" href="/feed.rss">
+	<link rel="alternate" type="application/atom+xml; charset=utf-8" title="Atom" href="https://example.org/atom">
+	<link rel="alternate" type="application/feed+json" href="feed.json">
+	<link rel="alternate" hreflang="de" href="/de/">
+</head>
+<body>
+	<a href="/about">About</a>
+	<a href="/blog/feed/">Blog <b>feed</b></a>
+	<a href="/feed.rss">Duplicate</a>
+	<a href="javascript:void(0)/rss">Script</a>
+</body>
+</html>`
+
+func TestDiscover(t *testing.T) {
+	page, _ := url.Parse("http://example.org/blog/index.html")
+	links, err := Discover(strings.NewReader(testPage), page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []FeedLink{
+		{"http://example.org/feed.rss", "RSS", "rss"},
+		{"https://example.org/atom", "Atom", "atom"},
+		{"http://example.org/blog/feed.json", "", "json"},
+		{"http://example.org/blog/feed/", "Blog feed", ""},
+	}
+
+	if !reflect.DeepEqual(links, expected) {
+		t.Fatalf("Expected %v - got %v", expected, links)
+	}
+
+	links, err = Discover(strings.NewReader(testPage), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []FeedLink{{"https://example.org/atom", "Atom", "atom"}}
+	if !reflect.DeepEqual(links, expected) {
+		t.Fatalf("Expected %v - got %v", expected, links)
+	}
+
+	links, err = Discover(strings.NewReader(`<base href="/relative/"><base href="http://example.com/"><a href="feed.rss">`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []FeedLink{{"http://example.com/feed.rss", "", "rss"}}
+	if !reflect.DeepEqual(links, expected) {
+		t.Fatalf("Expected %v - got %v", expected, links)
+	}
+}
+
+func TestFetchDiscover(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, `<html><head><link rel="alternate" type="application/rss+xml" href="/missing.xml">
+<link rel="alternate" type="application/rss+xml" href="/feed"></head></html>`)
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, testRss)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := &Fetcher{Client: server.Client()}
+	if _, err := fetcher.Fetch(context.Background(), server.URL, nil); err == nil {
+		t.Fatal("Expected Fetch to fail on HTML page")
+	}
+
+	result, err := fetcher.FetchDiscover(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.URL != server.URL+"/feed" {
+		t.Fatalf("Expected %q - got %q", server.URL+"/feed", result.URL)
+	}
+	if result.Feed.Type != "rss" {
+		t.Fatalf("Expected %q - got %q", "rss", result.Feed.Type)
+	}
+
+	fetcher.Parser = &Parser{MaxSize: 64}
+	_, err = fetcher.FetchDiscover(context.Background(), server.URL+"/feed", nil)
+	if _, ok := err.(*LimitError); !ok {
+		t.Fatalf("Expected *LimitError - got %v", err)
+	}
+}
@@ -19,44 +19,65 @@
 package feedparser
 
 import (
+	"context"
+	"fmt"
 	"io"
-	"io/ioutil"
-	"sort"
 	"time"
 )
 
 // parseFunc describes a function which implements a feed parser.
-type parseFunc func([]byte) (Feed, error)
+type parseFunc func(*parseState, []byte) (Feed, error)
 
-// parsers lists all known feed parsers.
-var parsers = []parseFunc{parseAtom, parseRss}
+// parsers maps root element names to the corresponding feed parsers.
+var parsers = map[string]parseFunc{
+	"feed": parseAtom,
+	"rss":  parseRss,
+}
 
 // Feed represents a generic feed.
 type Feed struct {
+	// Universally unique feed ID (atom only).
+	ID string
+
 	// Title for the feed.
 	Title string
 
 	// Feed type (either atom or rss).
 	Type string
 
-	// URL to the website.
+	// URL to the website, the primary link of the feed.
 	Link string
 
+	// All links of the feed.
+	Links []Link
+
+	// URL of the feed itself, as advertised by the feed.
+	Self string
+
 	// Description or subtitle for the feed.
 	Description string
 
-	// Categories the feed belongs to.
+	// Terms of the categories the feed belongs to.
 	Categories []string
 
+	// Categories the feed belongs to.
+	CategoryDetails []Category
+
 	// Email address of the feed author.
 	Author string
 
 	// Last time the feed was updated.
 	Updated time.Time
 
-	// URL to image for the feed.
+	// URL to image for the feed, same as the URL of the logo.
 	Image string
 
+	// Larger image for visual identification of the feed.
+	Logo FeedImage
+
+	// Small icon for visual identification of the feed.
+	Icon FeedImage
+
 	// Software used to generate the feed.
 	Generator string
 
@@ -65,6 +86,44 @@ type Feed struct {
 
 	// Feed Items
 	Items []Item
+
+	// Problems encountered while parsing the feed.
+	Warnings []Warning
+}
+
+// FeedImage represents an image of a feed, only the URL is always set.
+type FeedImage struct {
+	// URL of the image.
+	URL string
+
+	// Human readable description of the image.
+	Title string
+
+	// URL to the website the image links to.
+	Link string
+
+	// Width of the image in pixels.
+	Width int
+
+	// Height of the image in pixels.
+	Height int
+}
+
+// Warning describes a non-fatal problem encountered while parsing.
+type Warning struct {
+	// Human readable description of the problem.
+	Message string
+
+	// Position the problem refers to, if known.
+	Position Position
+}
+
+func (w Warning) String() string {
+	if w.Position.Line == 0 {
+		return w.Message
+	}
+
+	return fmt.Sprintf("%s: %s", w.Position, w.Message)
 }
 
 // Item represents a generic feed item.
@@ -72,48 +131,81 @@ type Item struct {
 	// Universally unique item ID.
 	ID string
 
+	// Whether the ID is a permanent URL of the item.
+	IsPermaLink bool
+
 	// Title of the item.
 	Title string
 
-	// URL for the item.
+	// URL for the item, the primary link of the item.
 	Link string
 
+	// All links of the item, including enclosures.
+	Links []Link
+
 	// Content of the item.
 	Content string
 
+	// Short summary of the item (atom only).
+	Summary string
+
 	// Email address of the item author.
 	Author string
 
-	// Categories the item belongs to.
+	// Terms of the categories the item belongs to.
 	Categories []string
 
+	// Categories the item belongs to.
+	CategoryDetails []Category
+
 	// Time the item was published.
 	PubDate time.Time
 
+	// Last time the item was updated.
+	Updated time.Time
+
+	// Position of the item in the original document.
+	Index int
+
 	// URL to media attachment.
 	Attachment string
+
+	// URL to the lead image of the item.
+	Image string
+
+	// Feed the item was copied from, if any.
+	Source *Source
 }
 
-// Parse tries to parse the content of the given reader. It also sorts all items
-// by there publication date. Meaning that the first item is guaranteed to be
-// the most recent one.
-func Parse(r io.Reader) (f Feed, err error) {
-	data, err := ioutil.ReadAll(r)
-	if err != nil {
-		return
-	}
+// Source describes the feed an item was copied from.
+type Source struct {
+	// Universally unique ID of the source feed.
+	ID string
 
-	for _, p := range parsers {
-		f, err = p(data)
-		if err == nil {
-			break
-		}
-	}
+	// Title of the source feed.
+	Title string
 
-	if err != nil {
-		return
-	}
+	// URL to the website of the source feed.
+	Link string
+
+	// URL of the source feed itself.
+	Self string
+
+	// Last time the source feed was updated.
+	Updated time.Time
+}
+
+// Parse tries to parse the content of the given reader using the
+// DefaultParser. It also sorts all items by there publication date.
+// Meaning that the first item is guaranteed to be the most recent one.
+// Items with the same publication date retain their document order, use
+// Feed.Sort to restore the document order entirely.
+func Parse(r io.Reader) (f Feed, err error) {
+	return DefaultParser.Parse(r)
+}
 
-	sort.Sort(byDate(f.Items))
-	return
+// ParseContext is like Parse but aborts parsing once the given context
+// is done.
+func ParseContext(ctx context.Context, r io.Reader) (f Feed, err error) {
+	return DefaultParser.ParseContext(ctx, r)
 }
@@ -0,0 +1,299 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptHeader is the Accept header sent by the Fetcher.
+const acceptHeader = "application/atom+xml, application/rss+xml, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8"
+
+// FetchState holds the cache validators returned by a previous fetch of
+// a feed. It should be persisted and passed to the next fetch of the
+// same feed.
+type FetchState struct {
+	// Entity tag of the feed (optional).
+	ETag string
+
+	// Last time the feed was modified as reported by the server (optional).
+	LastModified string
+}
+
+// Result represents the result of fetching a feed.
+type Result struct {
+	// Parsed feed, empty if the feed was not modified.
+	Feed Feed
+
+	// Whether the feed was not modified since the previous fetch.
+	NotModified bool
+
+	// Cache validators to persist for the next fetch.
+	State FetchState
+
+	// HTTP status code of the response.
+	StatusCode int
+
+	// URL the feed was fetched from after following all redirects.
+	URL string
+
+	// New URL of the feed if all followed redirects were permanent
+	// (301 or 308), empty otherwise.
+	PermanentRedirect string
+
+	// Whether the feed was permanently removed (410).
+	Gone bool
+
+	// Whether the feed advertises a self link which differs from the
+	// URL it was fetched from.
+	SelfLinkMismatch bool
+}
+
+// defaultMaxRedirects is the default maximum amount of redirects
+// followed by the Fetcher.
+const defaultMaxRedirects = 10
+
+var (
+	// ErrTooManyRedirects is returned if a fetch exceeds the maximum
+	// amount of redirects.
+	ErrTooManyRedirects = errors.New("feedparser: too many redirects")
+
+	// ErrRedirectLoop is returned if a fetch is redirected to a URL
+	// which was already visited.
+	ErrRedirectLoop = errors.New("feedparser: redirect loop")
+)
+
+// HTTPError is returned by the Fetcher if the server responded with an
+// unexpected status code.
+type HTTPError struct {
+	// HTTP status code of the response.
+	StatusCode int
+
+	// HTTP status line of the response.
+	Status string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("feedparser: unexpected HTTP status %s", e.Status)
+}
+
+// Fetcher fetches and parses feeds via HTTP. The zero value is a valid
+// Fetcher which uses http.DefaultClient and the DefaultParser. A Fetcher
+// is safe for concurrent use by multiple goroutines.
+type Fetcher struct {
+	// HTTP client used to perform requests (optional).
+	Client *http.Client
+
+	// Parser used to parse fetched feeds (optional).
+	Parser *Parser
+
+	// Value of the User-Agent header (optional).
+	UserAgent string
+
+	// Maximum amount of redirects to follow, defaults to 10 (optional).
+	MaxRedirects int
+}
+
+// Fetch fetches and parses the feed at the given URL. If a previous
+// state is given, a conditional request is performed and the returned
+// result reports whether the feed was modified since. Redirects are
+// followed using a redirect policy of the fetcher, which replaces the
+// CheckRedirect function of the client.
+func (f *Fetcher) Fetch(ctx context.Context, url string, prev *FetchState) (*Result, error) {
+	return f.fetch(ctx, url, prev, false)
+}
+
+// fetch implements Fetch, if discover is true feed autodiscovery is
+// performed if the fetched document is not a feed.
+func (f *Fetcher) fetch(ctx context.Context, url string, prev *FetchState, discover bool) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", acceptHeader)
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	if len(f.UserAgent) > 0 {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+
+	if prev != nil {
+		if len(prev.ETag) > 0 {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if len(prev.LastModified) > 0 {
+			req.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+	}
+
+	permanent := true
+	redirected := false
+
+	client := *f.client()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > f.maxRedirects() {
+			return ErrTooManyRedirects
+		}
+
+		for _, r := range via {
+			if r.URL.String() == req.URL.String() {
+				return ErrRedirectLoop
+			}
+		}
+
+		redirected = true
+		code := req.Response.StatusCode
+		if code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+			permanent = false
+		}
+
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Result{
+		StatusCode: resp.StatusCode,
+		URL:        resp.Request.URL.String(),
+		State: FetchState{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+	}
+
+	if redirected && permanent {
+		result.PermanentRedirect = result.URL
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusGone:
+		result.Gone = true
+		return result, nil
+	case resp.StatusCode == http.StatusNotModified:
+		result.NotModified = true
+		if prev != nil {
+			result.State = mergeState(*prev, result.State)
+		}
+		return result, nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, &HTTPError{resp.StatusCode, resp.Status}
+	}
+
+	body, err := decodeBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var page bytes.Buffer
+	if discover {
+		body = io.TeeReader(body, &page)
+	}
+
+	// Only documents which aren't feeds are used for discovery, limit
+	// errors truncate the page.
+	var limitErr *LimitError
+	result.Feed, err = f.parser().ParseContentType(ctx, body, resp.Header.Get("Content-Type"))
+	if err != nil && discover && ctx.Err() == nil && !errors.As(err, &limitErr) {
+		return f.discover(ctx, &page, resp.Request.URL, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(result.Feed.Self) > 0 {
+		self, err := resp.Request.URL.Parse(result.Feed.Self)
+		result.SelfLinkMismatch = err != nil || self.String() != result.URL
+	}
+
+	return result, nil
+}
+
+// maxRedirects returns the maximum amount of redirects to follow.
+func (f *Fetcher) maxRedirects() int {
+	if f.MaxRedirects <= 0 {
+		return defaultMaxRedirects
+	}
+
+	return f.MaxRedirects
+}
+
+// client returns the HTTP client used by the fetcher.
+func (f *Fetcher) client() *http.Client {
+	if f.Client == nil {
+		return http.DefaultClient
+	}
+
+	return f.Client
+}
+
+// parser returns the parser used by the fetcher.
+func (f *Fetcher) parser() *Parser {
+	if f.Parser == nil {
+		return DefaultParser
+	}
+
+	return f.Parser
+}
+
+// mergeState returns the previous state updated with all validators
+// present in the new state.
+func mergeState(prev, state FetchState) FetchState {
+	if len(state.ETag) > 0 {
+		prev.ETag = state.ETag
+	}
+	if len(state.LastModified) > 0 {
+		prev.LastModified = state.LastModified
+	}
+
+	return prev
+}
+
+// decodeBody returns a reader for the response body which undoes the
+// content encoding of the response.
+func decodeBody(resp *http.Response) (io.Reader, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+		return resp.Body, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(resp.Body)
+	case "deflate":
+		// Some servers send raw deflate data instead of the zlib
+		// format mandated by the specification.
+		br := bufio.NewReader(resp.Body)
+		header, _ := br.Peek(2)
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	case "br":
+		return brotli.NewReader(resp.Body), nil
+	default:
+		return nil, fmt.Errorf("feedparser: unsupported content encoding %q", encoding)
+	}
+}
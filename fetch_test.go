@@ -0,0 +1,185 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"github.com/andybalholm/brotli"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testETag = `"v1"`
+
+func feedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("If-None-Match") == testETag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("ETag", testETag)
+	w.Header().Set("Last-Modified", "Wed, 05 Aug 2015 10:00:00 GMT")
+	w.Header().Set("Content-Type", "application/rss+xml; charset=iso-8859-1")
+
+	var body io.Writer = w
+	switch encoding := r.URL.Query().Get("encoding"); encoding {
+	case "gzip":
+		zw := gzip.NewWriter(w)
+		defer zw.Close()
+		body = zw
+	case "deflate":
+		zw := zlib.NewWriter(w)
+		defer zw.Close()
+		body = zw
+	case "br":
+		bw := brotli.NewWriter(w)
+		defer bw.Close()
+		body = bw
+	}
+
+	if body != w {
+		w.Header().Set("Content-Encoding", r.URL.Query().Get("encoding"))
+	}
+	io.WriteString(body, strings.Replace(testRss, "Test feed", "Caf\xe9", 1))
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(feedHandler))
+	defer server.Close()
+
+	fetcher := &Fetcher{Client: server.Client()}
+	for _, encoding := range []string{"", "gzip", "deflate", "br"} {
+		result, err := fetcher.Fetch(context.Background(), server.URL+"/?encoding="+encoding, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.NotModified {
+			t.Fatal("Expected modified feed")
+		}
+		if result.State.ETag != testETag {
+			t.Fatalf("Expected %q - got %q", testETag, result.State.ETag)
+		}
+		if result.Feed.Description != "Café" {
+			t.Fatalf("Expected %q - got %q", "Café", result.Feed.Description)
+		}
+		if len(result.Feed.Items) != 3 {
+			t.Fatalf("Expected %d items - got %d", 3, len(result.Feed.Items))
+		}
+
+		result, err = fetcher.Fetch(context.Background(), server.URL, &result.State)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !result.NotModified {
+			t.Fatal("Expected unmodified feed")
+		}
+		if result.State.ETag != testETag {
+			t.Fatalf("Expected %q - got %q", testETag, result.State.ETag)
+		}
+	}
+}
+
+func TestFetchError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	fetcher := &Fetcher{Client: server.Client()}
+	_, err := fetcher.Fetch(context.Background(), server.URL, nil)
+
+	herr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("Expected *HTTPError - got %v", err)
+	}
+	if herr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected %d - got %d", http.StatusNotFound, herr.StatusCode)
+	}
+}
+
+type redirectpair struct {
+	Path      string
+	Permanent string
+	Gone      bool
+	Mismatch  bool
+	Err       error
+}
+
+func TestFetchRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/feed", http.HandlerFunc(feedHandler))
+	mux.Handle("/moved", http.RedirectHandler("/moved-again", http.StatusMovedPermanently))
+	mux.Handle("/moved-again", http.RedirectHandler("/feed", http.StatusPermanentRedirect))
+	mux.Handle("/found", http.RedirectHandler("/moved", http.StatusFound))
+	mux.Handle("/loop", http.RedirectHandler("/loop-again", http.StatusMovedPermanently))
+	mux.Handle("/loop-again", http.RedirectHandler("/loop", http.StatusMovedPermanently))
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/self", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Replace(testAtom, `<link href="http://example.org/"/>`,
+			`<link href="http://example.org/"/><link rel="self" href="/elsewhere"/>`, 1))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []redirectpair{
+		{"/feed", "", false, false, nil},
+		{"/moved", "/feed", false, false, nil},
+		{"/found", "", false, false, nil},
+		{"/loop", "", false, false, ErrRedirectLoop},
+		{"/gone", "", true, false, nil},
+		{"/self", "", false, true, nil},
+	}
+
+	fetcher := &Fetcher{Client: server.Client()}
+	for _, test := range tests {
+		result, err := fetcher.Fetch(context.Background(), server.URL+test.Path, nil)
+		if test.Err != nil {
+			if !errors.Is(err, test.Err) {
+				t.Fatalf("Expected %v - got %v", test.Err, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		var permanent string
+		if len(test.Permanent) > 0 {
+			permanent = server.URL + test.Permanent
+		}
+
+		if result.PermanentRedirect != permanent {
+			t.Fatalf("Expected %q - got %q", permanent, result.PermanentRedirect)
+		}
+		if result.Gone != test.Gone {
+			t.Fatalf("Expected %v - got %v", test.Gone, result.Gone)
+		}
+		if result.SelfLinkMismatch != test.Mismatch {
+			t.Fatalf("Expected %v - got %v", test.Mismatch, result.SelfLinkMismatch)
+		}
+	}
+
+	fetcher.MaxRedirects = 1
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/moved", nil); !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("Expected %v - got %v", ErrTooManyRedirects, err)
+	}
+}
@@ -0,0 +1,248 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Media contains the Media RSS and iTunes elements of an rss item or an
+// atom entry. It must be embedded before all other fields since fields
+// without a namespace also match namespaced elements with the same name
+// and the first matching field is used.
+type Media struct {
+	// Media RSS thumbnails (optional).
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+
+	// Media RSS media objects (optional).
+	Contents []MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+
+	// Media RSS groups of media objects (optional).
+	Groups []MediaGroup `xml:"http://search.yahoo.com/mrss/ group"`
+
+	// iTunes artwork (optional).
+	ItunesImage ItunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+}
+
+// MediaThumbnail represents the Media RSS thumbnail tag.
+type MediaThumbnail struct {
+	// URL of the thumbnail (required).
+	URL string `xml:"url,attr"`
+
+	// Width of the thumbnail in pixels (optional).
+	Width string `xml:"width,attr"`
+
+	// Height of the thumbnail in pixels (optional).
+	Height string `xml:"height,attr"`
+}
+
+// MediaContent represents the Media RSS content tag.
+type MediaContent struct {
+	// URL of the media object (recommended).
+	URL string `xml:"url,attr"`
+
+	// MIME type of the media object (optional).
+	Type string `xml:"type,attr"`
+
+	// Type of the media object, e.g. image or video (optional).
+	Medium string `xml:"medium,attr"`
+
+	// Width of the media object in pixels (optional).
+	Width string `xml:"width,attr"`
+
+	// Height of the media object in pixels (optional).
+	Height string `xml:"height,attr"`
+
+	// Thumbnails of the media object (optional).
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// MediaGroup represents the Media RSS group tag.
+type MediaGroup struct {
+	// Thumbnails of the group (optional).
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+
+	// Media objects of the group (optional).
+	Contents []MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+// ItunesImage represents the iTunes image tag.
+type ItunesImage struct {
+	// URL of the artwork (required).
+	Href string `xml:"href,attr"`
+}
+
+// thumbnails returns all thumbnails ordered by specificity, thumbnails
+// of the item come first.
+func (m *Media) thumbnails() []MediaThumbnail {
+	thumbnails := append([]MediaThumbnail(nil), m.Thumbnails...)
+	for _, group := range m.Groups {
+		thumbnails = append(thumbnails, group.Thumbnails...)
+	}
+	for _, content := range m.contents() {
+		thumbnails = append(thumbnails, content.Thumbnails...)
+	}
+
+	return thumbnails
+}
+
+// contents returns all media objects including those in groups.
+func (m *Media) contents() []MediaContent {
+	contents := append([]MediaContent(nil), m.Contents...)
+	for _, group := range m.Groups {
+		contents = append(contents, group.Contents...)
+	}
+
+	return contents
+}
+
+// enclosure describes a media object attached to an item.
+type enclosure struct {
+	url string
+	typ string
+}
+
+// findImage returns the URL of the lead image of an item, relative URLs
+// are resolved against the given link of the item. Images are taken
+// from Media RSS thumbnails and images, the iTunes image, the given
+// enclosures and the first image in the given content in that order.
+// Tracking pixels are skipped.
+func findImage(link string, media *Media, enclosures []enclosure, content string) string {
+	var image string
+	for _, thumbnail := range media.thumbnails() {
+		if len(thumbnail.URL) > 0 && !isPixel(thumbnail.Width, thumbnail.Height) {
+			image = thumbnail.URL
+			break
+		}
+	}
+
+	if len(image) == 0 {
+		for _, content := range media.contents() {
+			isImage := content.Medium == "image" || strings.HasPrefix(content.Type, "image/")
+			if isImage && len(content.URL) > 0 && !isPixel(content.Width, content.Height) {
+				image = content.URL
+				break
+			}
+		}
+	}
+
+	if len(image) == 0 {
+		image = media.ItunesImage.Href
+	}
+
+	if len(image) == 0 {
+		for _, enclosure := range enclosures {
+			if strings.HasPrefix(enclosure.typ, "image/") && len(enclosure.url) > 0 {
+				image = enclosure.url
+				break
+			}
+		}
+	}
+
+	if len(image) == 0 {
+		image = contentImage(content)
+	}
+
+	return resolveURL(link, image)
+}
+
+// contentImage returns the source of the first image in the given HTML
+// content which isn't a tracking pixel.
+func contentImage(content string) string {
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+		default:
+			continue
+		}
+
+		tok := z.Token()
+		if tok.DataAtom != atom.Img {
+			continue
+		}
+
+		src := strings.TrimSpace(attr(tok, "src"))
+		if len(src) > 0 && !isPixel(attr(tok, "width"), attr(tok, "height")) {
+			return src
+		}
+	}
+}
+
+// isPixel reports whether an image with the given width and height is
+// a tracking pixel.
+func isPixel(width, height string) bool {
+	for _, dimension := range []string{width, height} {
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(dimension), "px"))
+		if err == nil && n <= 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// feedImages resolves the URLs of the logo and icon of the feed. If the
+// feed has neither, the given iTunes image is used as logo or, if there
+// is none, the favicon of the website is used as icon.
+func feedImages(f *Feed, itunesImage string) {
+	f.Logo.URL = resolveURL(f.Link, f.Logo.URL)
+	f.Icon.URL = resolveURL(f.Link, f.Icon.URL)
+
+	if len(f.Logo.URL) == 0 && len(f.Icon.URL) == 0 {
+		f.Logo.URL = resolveURL(f.Link, itunesImage)
+		if len(f.Logo.URL) == 0 {
+			f.Icon.URL = favicon(f.Link)
+		}
+	}
+
+	f.Image = f.Logo.URL
+}
+
+// favicon returns the conventional favicon URL of the given website.
+func favicon(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return ""
+	}
+
+	return u.Scheme + "://" + u.Host + "/favicon.ico"
+}
+
+// resolveURL resolves the given reference against the given base URL.
+// If the base URL isn't absolute the reference is returned as is.
+func resolveURL(base, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if len(ref) == 0 {
+		return ""
+	}
+
+	b, err := url.Parse(strings.TrimSpace(base))
+	if err != nil || !b.IsAbs() {
+		return ref
+	}
+
+	u, err := b.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return u.String()
+}
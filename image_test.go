@@ -0,0 +1,159 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"strings"
+	"testing"
+)
+
+type imagepair struct {
+	Item  string
+	Image string
+}
+
+func TestItemImage(t *testing.T) {
+	tests := []imagepair{
+		{`<media:thumbnail url="http://example.org/pixel.gif" width="1" height="1"/>
+			<media:thumbnail url="http://example.org/thumb.jpg"/>
+			<itunes:image href="http://example.org/cover.jpg"/>`,
+			"http://example.org/thumb.jpg"},
+		{`<media:group>
+				<media:content url="http://example.org/a.mp4" type="video/mp4">
+					<media:thumbnail url="/thumb.jpg"/>
+				</media:content>
+			</media:group>`,
+			"http://example.org/thumb.jpg"},
+		{`<media:content url="http://example.org/a.mp4" medium="video"/>
+			<media:content url="http://example.org/a.jpg" medium="image"/>`,
+			"http://example.org/a.jpg"},
+		{`<itunes:image href="http://example.org/cover.jpg"/>
+			<enclosure url="http://example.org/a.png" length="1" type="image/png"/>`,
+			"http://example.org/cover.jpg"},
+		{`<enclosure url="http://example.org/a.mp3" length="1" type="audio/mpeg"/>
+			<description>&lt;img src="b.png"&gt;</description>`,
+			"http://example.org/posts/b.png"},
+		{`<enclosure url="http://example.org/a.png" length="1" type="image/png"/>`,
+			"http://example.org/a.png"},
+		{`<description>&lt;img src="/track.gif" width="1" height="1"&gt;&lt;img src="a.jpg"&gt;</description>`,
+			"http://example.org/posts/a.jpg"},
+		{`<description>No images</description>`, ""},
+	}
+
+	for _, test := range tests {
+		data := `<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"
+			xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel><item>
+			<link>http://example.org/posts/1</link>
+			<pubDate>Wed, 05 Aug 2015 10:00:00 GMT</pubDate>` + test.Item + `</item></channel></rss>`
+
+		feed, err := Parse(strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if image := feed.Items[0].Image; image != test.Image {
+			t.Fatalf("Expected %q - got %q", test.Image, image)
+		}
+	}
+
+	data := `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+		<updated>2015-08-05T18:30:02Z</updated>
+		<entry>
+			<updated>2015-08-05T18:30:02Z</updated>
+			<link href="http://example.org/posts/1"/>
+			<media:content url="http://example.org/a.jpg" medium="image"/>
+			<content type="html">&lt;p&gt;Content&lt;/p&gt;</content>
+		</entry>
+		<entry>
+			<updated>2015-08-05T18:30:02Z</updated>
+			<link href="http://example.org/posts/2"/>
+			<content type="html">&lt;p&gt;Content&lt;/p&gt;</content>
+			<media:content url="http://example.org/b.jpg" medium="image"/>
+		</entry>
+	</feed>`
+
+	feed, err := (&Parser{Order: DocumentOrder}).Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, image := range []string{"http://example.org/a.jpg", "http://example.org/b.jpg"} {
+		if feed.Items[i].Image != image {
+			t.Fatalf("Expected %q - got %q", image, feed.Items[i].Image)
+		}
+		if feed.Items[i].Content != "<p>Content</p>" {
+			t.Fatalf("Expected content to be retained - got %q", feed.Items[i].Content)
+		}
+	}
+}
+
+type feedimagepair struct {
+	Data string
+	Logo FeedImage
+	Icon FeedImage
+}
+
+func TestFeedImage(t *testing.T) {
+	tests := []feedimagepair{
+		{`<feed xmlns="http://www.w3.org/2005/Atom">
+			<updated>2015-08-05T18:30:02Z</updated>
+			<link href="http://example.org/blog/"/>
+			<logo>/logo.png</logo>
+			<icon>http://example.org/icon.png</icon>
+		</feed>`,
+			FeedImage{URL: "http://example.org/logo.png"},
+			FeedImage{URL: "http://example.org/icon.png"}},
+		{`<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+			<link>http://example.org/</link>
+			<itunes:image href="http://example.org/cover.jpg"/>
+			<image>
+				<url>http://example.org/logo.png</url>
+				<title>Logo</title>
+				<link>http://example.org/</link>
+				<width>88</width>
+				<height>31</height>
+			</image>
+		</channel></rss>`,
+			FeedImage{"http://example.org/logo.png", "Logo", "http://example.org/", 88, 31},
+			FeedImage{}},
+		{`<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+			<link>http://example.org/</link>
+			<itunes:image href="http://example.org/cover.jpg"/>
+		</channel></rss>`,
+			FeedImage{URL: "http://example.org/cover.jpg"},
+			FeedImage{}},
+		{`<rss version="2.0"><channel>
+			<link>https://Example.org/blog/</link>
+		</channel></rss>`,
+			FeedImage{},
+			FeedImage{URL: "https://Example.org/favicon.ico"}},
+	}
+
+	for _, test := range tests {
+		feed, err := Parse(strings.NewReader(test.Data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if feed.Logo != test.Logo {
+			t.Fatalf("Expected logo %+v - got %+v", test.Logo, feed.Logo)
+		}
+		if feed.Icon != test.Icon {
+			t.Fatalf("Expected icon %+v - got %+v", test.Icon, feed.Icon)
+		}
+		if feed.Image != test.Logo.URL {
+			t.Fatalf("Expected image %q - got %q", test.Logo.URL, feed.Image)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// trackingParams lists query parameters used for tracking, they are
+// removed from URLs before computing item keys. Parameters ending with
+// an asterisk match all parameters with the given prefix.
+var trackingParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+}
+
+// Key returns a string identifying the item across fetches of its feed,
+// which can be used to deduplicate items. If the item has an ID the key
+// is the ID, IDs which are permanent links (see Item.IsPermaLink) are
+// normalized while all other IDs are opaque and only trimmed. Otherwise,
+// the key is a hash of the normalized link, the title, the normalized
+// attachment URL and the publication date of the item, prefixed with
+// "sha256:".
+//
+// Keys are stable across versions of this package, changing how they
+// are computed is considered a breaking change.
+func (i Item) Key() string {
+	if id := strings.TrimSpace(i.ID); len(id) > 0 {
+		if i.IsPermaLink {
+			return normalizeLink(id)
+		}
+		return id
+	}
+
+	var pubDate string
+	if !i.PubDate.IsZero() {
+		pubDate = i.PubDate.UTC().Format(time.RFC3339)
+	}
+
+	h := sha256.New()
+	for _, field := range []string{
+		normalizeLink(i.Link),
+		strings.TrimSpace(i.Title),
+		normalizeLink(i.Attachment),
+		pubDate,
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeLink is like normalizeURL but returns the trimmed link if it
+// isn't an absolute HTTP URL.
+func normalizeLink(link string) string {
+	if u, ok := normalizeURL(link); ok {
+		return u
+	}
+
+	return strings.TrimSpace(link)
+}
+
+// normalizeURL normalizes the given absolute HTTP URL by lowercasing
+// its scheme and host, removing trailing slashes from its path and
+// removing tracking parameters from its query. It reports false if the
+// given string isn't an absolute HTTP URL.
+func normalizeURL(rawurl string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawurl))
+	if err != nil || len(u.Host) == 0 {
+		return "", false
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	u.Host = strings.ToLower(u.Host)
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+
+	if len(u.RawQuery) > 0 {
+		query := u.Query()
+		for name := range query {
+			if isTrackingParam(name) {
+				query.Del(name)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String(), true
+}
+
+// isTrackingParam reports whether the query parameter with the given
+// name is used for tracking.
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, param := range trackingParams {
+		if strings.HasSuffix(param, "*") && strings.HasPrefix(name, param[:len(param)-1]) {
+			return true
+		} else if name == param {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type keypair struct {
+	Item Item
+	Key  string
+}
+
+func TestKey(t *testing.T) {
+	date := time.Date(2015, time.August, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []keypair{
+		{Item{ID: " urn:uuid:1 "}, "urn:uuid:1"},
+		{Item{ID: "HTTP://Example.ORG/a/?utm_source=x&b=2&a=1#c", IsPermaLink: true}, "http://example.org/a?a=1&b=2#c"},
+		{Item{ID: "https://example.org/?fbclid=1&gclid=2", IsPermaLink: true}, "https://example.org"},
+		{Item{ID: " https://Example.org/a/?utm_source=x "}, "https://Example.org/a/?utm_source=x"},
+		{Item{Link: "http://example.org/a", Title: "A", PubDate: date},
+			"sha256:0987017c2b98bf7f70c21596b075634ec74ed28f47e8ba5132fa1c8a4b6c5510"},
+	}
+
+	for _, test := range tests {
+		if key := test.Item.Key(); key != test.Key {
+			t.Fatalf("Expected %q - got %q", test.Key, key)
+		}
+	}
+
+	a := Item{Link: "http://EXAMPLE.org/a/?utm_medium=rss", Title: " A ", PubDate: date.In(time.FixedZone("", 3600))}
+	b := Item{Link: "http://example.org/a", Title: "A", PubDate: date, Index: 3}
+	if a.Key() != b.Key() {
+		t.Fatalf("Expected equal keys - got %q and %q", a.Key(), b.Key())
+	}
+
+	b.Attachment = "http://example.org/a.mp3"
+	if a.Key() == b.Key() {
+		t.Fatalf("Expected different keys - got %q", a.Key())
+	}
+}
+
+type permalinkpair struct {
+	GUID        string
+	Link        string
+	IsPermaLink bool
+}
+
+func TestPermaLink(t *testing.T) {
+	tests := []permalinkpair{
+		{`<guid>http://example.org/a</guid>`, "http://example.org/a", true},
+		{`<guid isPermaLink="true">http://example.org/a</guid>`, "http://example.org/a", true},
+		{`<guid isPermaLink="false">http://example.org/a</guid>`, "", false},
+		{`<guid>a</guid>`, "", true},
+		{`<guid>http://example.org/a</guid><link>http://example.org/b</link>`, "http://example.org/b", true},
+		{``, "", false},
+	}
+
+	for _, test := range tests {
+		data := `<rss version="2.0"><channel><item><title>T</title>` + test.GUID +
+			`<pubDate>Wed, 05 Aug 2015 10:00:00 GMT</pubDate></item></channel></rss>`
+
+		feed, err := Parse(strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		item := feed.Items[0]
+		if item.Link != test.Link {
+			t.Fatalf("Expected link %q - got %q", test.Link, item.Link)
+		}
+		if item.IsPermaLink != test.IsPermaLink {
+			t.Fatalf("Expected IsPermaLink %v - got %v", test.IsPermaLink, item.IsPermaLink)
+		}
+	}
+}
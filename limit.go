@@ -0,0 +1,209 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// LimitError is returned if a document exceeds a limit configured on
+// the Parser.
+type LimitError struct {
+	// Name of the exceeded Parser field (e.g. MaxSize).
+	Limit string
+
+	// Configured value of the exceeded limit.
+	Max int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("feedparser: document exceeds %s of %d", e.Limit, e.Max)
+}
+
+// ctxReader is an io.Reader which fails once the context is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.r.Read(p)
+}
+
+// sizeReader is an io.Reader which fails once more than the maximum
+// amount of bytes were read.
+type sizeReader struct {
+	r   io.Reader
+	max int64
+	n   int64
+}
+
+func (s *sizeReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.n += int64(n)
+	if s.n > s.max {
+		return 0, &LimitError{"MaxSize", s.max}
+	}
+
+	return n, err
+}
+
+// limitReader is an xml.TokenReader which checks the context and
+// enforces the limits of the parser for each token it reads. It also
+// records the positions of elements if requested.
+type limitReader struct {
+	s *parseState
+	d *xml.Decoder
+
+	// Whether positions of elements are recorded.
+	record bool
+
+	// All currently open elements.
+	frames []frame
+
+	// Number of items encountered so far.
+	items int
+}
+
+// frame represents an open element.
+type frame struct {
+	// Local name of the element.
+	name string
+
+	// Indexed path of the element, empty if it is not recorded.
+	path string
+
+	// Number of child elements encountered so far by local name.
+	children map[string]int
+
+	// Whether the element is a field of the feed or an item, or
+	// contained in one.
+	inField bool
+
+	// Index of the frame the text of the element is counted against,
+	// which is the enclosing field or the element itself.
+	field int
+
+	// Length of the text counted against the element so far.
+	text int
+}
+
+// limit returns a token reader for the given decoder which enforces
+// the limits of the parser and optionally records the positions of
+// elements.
+func (s *parseState) limit(d *xml.Decoder, record bool) xml.TokenReader {
+	l := &limitReader{s: s, d: d, record: record, frames: []frame{{}}}
+	if record {
+		if s.positions == nil {
+			s.positions = make(map[string]Position)
+		}
+		l.frames[0].children = make(map[string]int)
+	}
+
+	return l
+}
+
+func (l *limitReader) Token() (xml.Token, error) {
+	if err := l.s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tok, err := l.d.Token()
+	if err != nil {
+		return tok, err
+	}
+
+	p := l.s.parser
+	switch t := tok.(type) {
+	case xml.StartElement:
+		parent := &l.frames[len(l.frames)-1]
+		if isItem(parent.name, t.Name.Local) {
+			l.items++
+			if p.MaxItems > 0 && l.items > p.MaxItems {
+				return nil, &LimitError{"MaxItems", int64(p.MaxItems)}
+			}
+		}
+
+		f := l.recordFrame(parent, t.Name.Local)
+		f.field = len(l.frames)
+		if parent.inField {
+			f.inField, f.field = true, parent.field
+		} else if isContainer(parent.name) && !isContainer(f.name) {
+			f.inField = true
+		}
+
+		l.frames = append(l.frames, f)
+		if p.MaxDepth > 0 && len(l.frames)-1 > p.MaxDepth {
+			return nil, &LimitError{"MaxDepth", int64(p.MaxDepth)}
+		}
+	case xml.EndElement:
+		if len(l.frames) > 1 {
+			l.frames = l.frames[:len(l.frames)-1]
+		}
+	case xml.CharData:
+		// Indentation between child elements adds up in elements
+		// containing many children and is therefore not counted.
+		if len(bytes.TrimSpace(t)) == 0 {
+			break
+		}
+
+		field := &l.frames[l.frames[len(l.frames)-1].field]
+		field.text += len(t)
+		if p.MaxTextLength > 0 && field.text > p.MaxTextLength {
+			return nil, &LimitError{"MaxTextLength", int64(p.MaxTextLength)}
+		}
+	}
+
+	return tok, nil
+}
+
+// recordFrame records the position of a newly opened element with the
+// given parent and returns its frame.
+func (l *limitReader) recordFrame(parent *frame, name string) frame {
+	f := frame{name: name}
+	if !l.record || parent.children == nil {
+		return f
+	}
+
+	parent.children[name]++
+	f.path = fmt.Sprintf("%s/%s[%d]", parent.path, name, parent.children[name])
+	l.s.positions[f.path] = decoderPosition(l.d)
+
+	if len(l.frames) < maxPositionDepth {
+		f.children = make(map[string]int)
+	}
+
+	return f
+}
+
+// isContainer reports whether the children of an element with the given
+// local name are fields of a feed or an item.
+func isContainer(name string) bool {
+	return name == "feed" || name == "channel" || name == "entry" || name == "item"
+}
+
+// isItem reports whether an element with the given local name, whose
+// parent has the given local name, represents a feed item.
+func isItem(parent, name string) bool {
+	return (parent == "feed" && name == "entry") ||
+		(parent == "channel" && name == "item")
+}
@@ -0,0 +1,145 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// relPrefix is the IRI prefix of link relations registered with IANA,
+// relations with this prefix are equivalent to the bare relation name.
+const relPrefix = "http://www.iana.org/assignments/relation/"
+
+// Link represents a link of a feed or an item.
+type Link struct {
+	// URL of the link.
+	Href string
+
+	// Link relation type, e.g. alternate, self, enclosure or replies.
+	// Links without relation type are alternate links.
+	Rel string
+
+	// Media type of the resource (optional).
+	Type string
+
+	// Language of the resource (optional).
+	HrefLang string
+
+	// Human readable information about the link (optional).
+	Title string
+
+	// Length of the resource in bytes, zero if unknown (optional).
+	Length int64
+}
+
+// LinkPolicy selects the primary link of a feed or an item, which is
+// used as Feed.Link and Item.Link, from all of its links. If there is no
+// suitable link the zero Link is returned.
+type LinkPolicy func(links []Link) Link
+
+// DefaultLinkPolicy is the LinkPolicy used if none is configured. It
+// selects the first link matching the following criteria, in order: an
+// alternate link to an HTML page, an alternate link without media type,
+// any alternate link, any link to an HTML page, a self link, a related
+// link and finally a via link.
+func DefaultLinkPolicy(links []Link) Link {
+	return selectLink(links,
+		func(l Link) bool { return l.Rel == "alternate" && isHTML(l.Type) },
+		func(l Link) bool { return l.Rel == "alternate" && len(l.Type) == 0 },
+		func(l Link) bool { return l.Rel == "alternate" },
+		func(l Link) bool { return isHTML(l.Type) },
+		func(l Link) bool { return l.Rel == "self" },
+		func(l Link) bool { return l.Rel == "related" },
+		func(l Link) bool { return l.Rel == "via" },
+	)
+}
+
+// PreferLanguage returns a LinkPolicy which selects the first alternate
+// link in the given language, matched by prefix, e.g. en matches en-US.
+// If there is no such link, the DefaultLinkPolicy is used instead.
+func PreferLanguage(lang string) LinkPolicy {
+	lang = strings.ToLower(lang)
+	return func(links []Link) Link {
+		link := selectLink(links, func(l Link) bool {
+			hrefLang := strings.ToLower(l.HrefLang)
+			return l.Rel == "alternate" && (hrefLang == lang || strings.HasPrefix(hrefLang, lang+"-"))
+		})
+		if len(link.Href) == 0 {
+			return DefaultLinkPolicy(links)
+		}
+
+		return link
+	}
+}
+
+// selectLink returns the first link matching the first predicate which
+// matches any of the given links.
+func selectLink(links []Link, predicates ...func(Link) bool) Link {
+	for _, matches := range predicates {
+		for _, link := range links {
+			if len(link.Href) > 0 && matches(link) {
+				return link
+			}
+		}
+	}
+
+	return Link{}
+}
+
+// findRel returns the first link with the given relation type.
+func findRel(links []Link, rel string) Link {
+	return selectLink(links, func(l Link) bool { return l.Rel == rel })
+}
+
+// isHTML reports whether the given media type refers to an HTML page.
+func isHTML(mediaType string) bool {
+	mediaType, _, _ = mime.ParseMediaType(mediaType)
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// primaryLink selects the primary link using the configured policy.
+func (s *parseState) primaryLink(links []Link) Link {
+	if s.parser.LinkPolicy == nil {
+		return DefaultLinkPolicy(links)
+	}
+
+	return s.parser.LinkPolicy(links)
+}
+
+// newLink creates a link with the given relation type, defaulting to
+// alternate, and the given length.
+func newLink(href, rel, typ, hrefLang, title, length string) Link {
+	rel = strings.TrimPrefix(strings.TrimSpace(rel), relPrefix)
+	if len(rel) == 0 {
+		rel = "alternate"
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(length), 10, 64)
+	if err != nil || n < 0 {
+		n = 0
+	}
+
+	return Link{strings.TrimSpace(href), rel, strings.TrimSpace(typ), hrefLang, title, n}
+}
+
+// atomLinks converts atom links to generic links.
+func atomLinks(links []AtomLink) (converted []Link) {
+	for _, l := range links {
+		converted = append(converted, newLink(l.Href, l.Rel, l.Type, l.HrefLang, l.Title, l.Length))
+	}
+
+	return
+}
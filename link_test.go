@@ -0,0 +1,132 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLinks = `<feed xmlns="http://www.w3.org/2005/Atom">
+	<updated>2015-08-05T18:30:02Z</updated>
+	<link rel="self" href="http://example.org/atom.xml"/>
+	<link rel="hub" href="http://hub.example.org/"/>
+	<link href="http://example.org/"/>
+	<entry>
+		<updated>2015-08-05T18:30:02Z</updated>
+		<link rel="replies" type="application/atom+xml" href="http://example.org/1/comments.xml"/>
+		<link rel="alternate" type="text/html" hreflang="en" href="http://example.org/en/1"/>
+		<link rel="alternate" type="text/html" hreflang="de-AT" href="http://example.org/de/1"/>
+		<link rel="http://www.iana.org/assignments/relation/enclosure" type="audio/mpeg" length="1337" href="http://example.org/1.mp3"/>
+	</entry>
+	<entry>
+		<updated>2015-08-05T18:30:02Z</updated>
+		<link rel="edit" href="http://example.org/edit/2"/>
+		<link rel="via" href="http://example.com/2"/>
+	</entry>
+</feed>`
+
+type linkpair struct {
+	Policy LinkPolicy
+	Links  []string
+}
+
+func TestLinks(t *testing.T) {
+	feed, err := (&Parser{Order: DocumentOrder}).Parse(strings.NewReader(testLinks))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Link{
+		{Href: "http://example.org/atom.xml", Rel: "self"},
+		{Href: "http://hub.example.org/", Rel: "hub"},
+		{Href: "http://example.org/", Rel: "alternate"},
+	}
+	if !reflect.DeepEqual(feed.Links, expected) {
+		t.Fatalf("Expected %v - got %v", expected, feed.Links)
+	}
+
+	enclosure := Link{"http://example.org/1.mp3", "enclosure", "audio/mpeg", "", "", 1337}
+	if links := feed.Items[0].Links; len(links) != 4 || links[3] != enclosure {
+		t.Fatalf("Expected enclosure %v - got %v", enclosure, links)
+	}
+	if feed.Items[0].Attachment != enclosure.Href {
+		t.Fatalf("Expected attachment %q - got %q", enclosure.Href, feed.Items[0].Attachment)
+	}
+
+	tests := []linkpair{
+		{nil, []string{"http://example.org/", "http://example.org/en/1", "http://example.com/2"}},
+		{PreferLanguage("de"), []string{"http://example.org/", "http://example.org/de/1", "http://example.com/2"}},
+		{PreferLanguage("fr"), []string{"http://example.org/", "http://example.org/en/1", "http://example.com/2"}},
+		{func(links []Link) Link { return links[0] }, []string{"http://example.org/atom.xml",
+			"http://example.org/1/comments.xml", "http://example.org/edit/2"}},
+	}
+
+	for _, test := range tests {
+		parser := &Parser{Order: DocumentOrder, LinkPolicy: test.Policy}
+		feed, err := parser.Parse(strings.NewReader(testLinks))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		links := []string{feed.Link}
+		for _, item := range feed.Items {
+			links = append(links, item.Link)
+		}
+
+		if !reflect.DeepEqual(links, test.Links) {
+			t.Fatalf("Expected %q - got %q", test.Links, links)
+		}
+	}
+}
+
+func TestRssLinks(t *testing.T) {
+	data := `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>
+		<link>http://example.org/</link>
+		<atom:link rel="self" type="application/rss+xml" href="http://example.org/rss.xml"/>
+		<item>
+			<pubDate>Wed, 05 Aug 2015 10:00:00 GMT</pubDate>
+			<guid>http://example.org/1</guid>
+			<comments>http://example.org/1#comments</comments>
+			<enclosure url="http://example.org/1.mp3" length="42" type="audio/mpeg"/>
+		</item>
+	</channel></rss>`
+
+	feed, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Link{
+		{Href: "http://example.org/", Rel: "alternate"},
+		{Href: "http://example.org/rss.xml", Rel: "self", Type: "application/rss+xml"},
+	}
+	if !reflect.DeepEqual(feed.Links, expected) {
+		t.Fatalf("Expected %v - got %v", expected, feed.Links)
+	}
+
+	expected = []Link{
+		{Href: "http://example.org/1", Rel: "alternate"},
+		{Href: "http://example.org/1.mp3", Rel: "enclosure", Type: "audio/mpeg", Length: 42},
+		{Href: "http://example.org/1#comments", Rel: "replies"},
+	}
+	if !reflect.DeepEqual(feed.Items[0].Links, expected) {
+		t.Fatalf("Expected %v - got %v", expected, feed.Items[0].Links)
+	}
+
+	if feed.Link != "http://example.org/" || feed.Items[0].Link != "http://example.org/1" {
+		t.Fatalf("Unexpected primary links %q and %q", feed.Link, feed.Items[0].Link)
+	}
+}
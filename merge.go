@@ -0,0 +1,126 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Merger combines multiple feeds into a single feed according to its
+// configuration. The zero value is a valid Merger which behaves like the
+// Merge function.
+type Merger struct {
+	// Title of the merged feed (optional).
+	Title string
+
+	// Maximum number of items in the merged feed (optional).
+	MaxItems int
+
+	// Maximum number of items taken from a single feed, the most
+	// recent ones are taken (optional).
+	MaxPerSource int
+}
+
+// Merge combines the items of the given feeds into a single feed using
+// the zero Merger.
+func Merge(feeds ...Feed) Feed {
+	return (&Merger{}).Merge(feeds...)
+}
+
+// Merge combines the items of the given feeds into a single feed. The
+// items are ordered by publication date, most recent first. Items with
+// the same link or URL key are only included once, other keys, e.g. rss
+// guids like "1", are only unique within their source. Items which
+// don't have a source yet are annotated with the feed they were taken
+// from.
+// The Index of each item is set to its position in the merged feed.
+func (m *Merger) Merge(feeds ...Feed) Feed {
+	merged := Feed{Title: m.Title}
+
+	var items []scopedItem
+	for i, feed := range feeds {
+		if feed.Updated.After(merged.Updated) {
+			merged.Updated = feed.Updated
+		}
+
+		source := &Source{
+			ID:      feed.ID,
+			Title:   feed.Title,
+			Link:    feed.Link,
+			Self:    feed.Self,
+			Updated: feed.Updated,
+		}
+
+		feedItems := append([]Item(nil), feed.Items...)
+		sort.Stable(byDate(feedItems))
+		if m.MaxPerSource > 0 && len(feedItems) > m.MaxPerSource {
+			feedItems = feedItems[:m.MaxPerSource]
+		}
+
+		for _, item := range feedItems {
+			if item.Source == nil {
+				item.Source = source
+			}
+			items = append(items, scopedItem{item, item.Source.scope(i)})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].PubDate.After(items[j].PubDate)
+	})
+
+	keys := make(map[string]bool)
+	links := make(map[string]bool)
+	for _, item := range items {
+		if m.MaxItems > 0 && len(merged.Items) >= m.MaxItems {
+			break
+		}
+
+		key, link := item.Key(), normalizeLink(item.Link)
+		if _, ok := normalizeURL(key); !ok {
+			key = item.scope + "\x00" + key
+		}
+		if keys[key] || (len(link) > 0 && links[link]) {
+			continue
+		}
+		keys[key] = true
+		if len(link) > 0 {
+			links[link] = true
+		}
+
+		item.Index = len(merged.Items)
+		merged.Items = append(merged.Items, item.Item)
+	}
+
+	return merged
+}
+
+// scopedItem is an item together with the scope of its key.
+type scopedItem struct {
+	Item
+	scope string
+}
+
+// scope returns a string identifying the source, the given index of
+// the merged feed is used if the source has neither ID, self link, link
+// nor title.
+func (s *Source) scope(index int) string {
+	for _, id := range []string{s.ID, s.Self, s.Link, s.Title} {
+		if len(id) > 0 {
+			return id
+		}
+	}
+
+	return strconv.Itoa(index)
+}
@@ -0,0 +1,88 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"testing"
+	"time"
+)
+
+type mergepair struct {
+	Merger *Merger
+	IDs    string
+}
+
+func TestMerge(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2015, time.August, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	a := Feed{ID: "urn:a", Title: "A", Updated: day(6), Items: []Item{
+		{ID: "a1", PubDate: day(1)},
+		{ID: "a2", PubDate: day(4)},
+		{ID: "a3", PubDate: day(5), Link: "http://example.org/x"},
+		{ID: "http://example.org/p", PubDate: day(2), IsPermaLink: true},
+	}}
+	b := Feed{Title: "B", Link: "http://example.org", Updated: day(5), Items: []Item{
+		{ID: "b1", PubDate: day(3)},
+		{ID: "a1", PubDate: day(1)},
+		{ID: "b2", PubDate: day(2), Link: "http://EXAMPLE.org/x/"},
+		{ID: "b3", PubDate: day(6), Source: &Source{Title: "C"}},
+		{ID: "http://Example.org/p/", PubDate: day(2), IsPermaLink: true},
+		{ID: "b1", PubDate: day(3)},
+	}}
+
+	tests := []mergepair{
+		{&Merger{}, "b3,a3,a2,b1,http://example.org/p,a1,a1"},
+		{&Merger{MaxItems: 2}, "b3,a3"},
+		{&Merger{MaxPerSource: 1}, "b3,a3"},
+		{&Merger{MaxPerSource: 2}, "b3,a3,a2,b1"},
+	}
+
+	for _, test := range tests {
+		feed := test.Merger.Merge(a, b)
+
+		var ids string
+		for i, item := range feed.Items {
+			if i > 0 {
+				ids += ","
+			}
+			ids += item.ID
+
+			if item.Index != i {
+				t.Fatalf("Expected index %d - got %d", i, item.Index)
+			}
+		}
+
+		if ids != test.IDs {
+			t.Fatalf("Expected %q - got %q", test.IDs, ids)
+		}
+	}
+
+	feed := Merge(a, b)
+	if !feed.Updated.Equal(day(6)) {
+		t.Fatalf("Expected %v - got %v", day(6), feed.Updated)
+	}
+
+	sources := map[string]string{"b3": "C", "a3": "A", "b1": "B"}
+	for _, item := range feed.Items {
+		if title, ok := sources[item.ID]; ok && item.Source.Title != title {
+			t.Fatalf("Expected source %q for %s - got %q", title, item.ID, item.Source.Title)
+		}
+	}
+
+	if feed.Items[1].Source.ID != "urn:a" {
+		t.Fatalf("Expected source ID %q - got %q", "urn:a", feed.Items[1].Source.ID)
+	}
+}
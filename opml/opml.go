@@ -0,0 +1,223 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package opml implements reading and writing of OPML subscription
+// lists as exchanged by feed readers.
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/nmeum/go-feedparser"
+	"golang.org/x/net/html/charset"
+	"io"
+	"time"
+)
+
+// OPML represents an OPML document.
+type OPML struct {
+	// XMLName.
+	XMLName xml.Name `xml:"opml"`
+
+	// OPML version, usually 2.0 (required).
+	Version string `xml:"version,attr"`
+
+	// Metadata of the document (required).
+	Head Head `xml:"head"`
+
+	// Content of the document (required).
+	Body Body `xml:"body"`
+}
+
+// Head represents the head of an OPML document.
+type Head struct {
+	// Title of the document (optional).
+	Title string `xml:"title,omitempty"`
+
+	// Time the document was created (optional).
+	DateCreated string `xml:"dateCreated,omitempty"`
+
+	// Last time the document was modified (optional).
+	DateModified string `xml:"dateModified,omitempty"`
+
+	// Name of the owner of the document (optional).
+	OwnerName string `xml:"ownerName,omitempty"`
+
+	// Email address of the owner of the document (optional).
+	OwnerEmail string `xml:"ownerEmail,omitempty"`
+
+	// URL of a page which allows contacting the owner (optional).
+	OwnerID string `xml:"ownerId,omitempty"`
+
+	// URL of the documentation of the format (optional).
+	Docs string `xml:"docs,omitempty"`
+}
+
+// Body represents the body of an OPML document.
+type Body struct {
+	// Top level outlines (required).
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline represents an outline, which is either a subscription or a
+// folder containing further outlines.
+type Outline struct {
+	// Text of the outline (required).
+	Text string `xml:"text,attr"`
+
+	// Title of the outline, usually the same as Text (optional).
+	Title string `xml:"title,attr,omitempty"`
+
+	// Type of the outline, rss for subscriptions (optional).
+	Type string `xml:"type,attr,omitempty"`
+
+	// URL of the subscribed feed (required for subscriptions).
+	XMLURL string `xml:"xmlUrl,attr,omitempty"`
+
+	// URL of the website of the subscribed feed (optional).
+	HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+
+	// Description of the subscribed feed (optional).
+	Description string `xml:"description,attr,omitempty"`
+
+	// Language of the subscribed feed (optional).
+	Language string `xml:"language,attr,omitempty"`
+
+	// Comma-separated category paths (optional).
+	Category string `xml:"category,attr,omitempty"`
+
+	// Outlines contained in this outline (optional).
+	Outlines []Outline `xml:"outline"`
+}
+
+// Subscription represents a subscription outline together with the
+// folders it is contained in.
+type Subscription struct {
+	Outline
+
+	// Text of all folders containing the outline, outermost first.
+	Folders []string
+}
+
+// IsFolder reports whether the outline is a folder, that is an outline
+// which doesn't refer to a feed.
+func (o *Outline) IsFolder() bool {
+	return len(o.XMLURL) == 0
+}
+
+// Subscriptions returns all subscriptions of the document in document
+// order.
+func (o *OPML) Subscriptions() []Subscription {
+	return subscriptions(o.Body.Outlines, nil)
+}
+
+// subscriptions returns all subscriptions in the given outlines, which
+// are contained in the given folders.
+func subscriptions(outlines []Outline, folders []string) (subs []Subscription) {
+	for _, outline := range outlines {
+		if !outline.IsFolder() {
+			subs = append(subs, Subscription{outline, folders})
+		}
+
+		if len(outline.Outlines) > 0 {
+			inner := append(folders[:len(folders):len(folders)], outline.Text)
+			subs = append(subs, subscriptions(outline.Outlines, inner)...)
+		}
+	}
+
+	return
+}
+
+// ParseOPML parses the OPML document read from the given reader.
+func ParseOPML(r io.Reader) (*OPML, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	var o OPML
+	if err := decoder.Decode(&o); err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// WriteOPML writes the given OPML document to the given writer.
+func WriteOPML(w io.Writer, o *OPML) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(o); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// NewOutline creates a subscription outline for the given feed, which
+// is located at the given URL. If the URL is empty, the self link of the
+// feed is used.
+func NewOutline(f feedparser.Feed, xmlURL string) Outline {
+	if len(xmlURL) == 0 {
+		xmlURL = f.Self
+	}
+
+	return Outline{
+		Text:        f.Title,
+		Title:       f.Title,
+		Type:        "rss",
+		XMLURL:      xmlURL,
+		HTMLURL:     f.Link,
+		Description: f.Description,
+	}
+}
+
+// NewFolder creates a folder outline containing the given outlines.
+func NewFolder(name string, outlines ...Outline) Outline {
+	return Outline{Text: name, Title: name, Outlines: outlines}
+}
+
+// Feed is a feed together with the URL it is located at.
+type Feed struct {
+	// URL of the feed, the self link of the feed is used if empty.
+	URL string
+
+	// Parsed feed.
+	Feed feedparser.Feed
+}
+
+// FromFeeds creates an OPML document with the given title subscribing
+// to the given feeds in order. An error is returned if the URL of a
+// feed is unknown.
+func FromFeeds(title string, feeds []Feed) (*OPML, error) {
+	o := &OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123),
+		},
+	}
+
+	for i, f := range feeds {
+		outline := NewOutline(f.Feed, f.URL)
+		if len(outline.XMLURL) == 0 {
+			return nil, fmt.Errorf("opml: URL of feed %d (%q) is unknown", i, f.Feed.Title)
+		}
+		o.Body.Outlines = append(o.Body.Outlines, outline)
+	}
+
+	return o, nil
+}
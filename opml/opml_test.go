@@ -0,0 +1,110 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package opml
+
+import (
+	"bytes"
+	"github.com/nmeum/go-feedparser"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testOPML = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<opml version="2.0">
+	<head>
+		<title>Subscriptions</title>
+	</head>
+	<body>
+		<outline text="Go Blog" type="rss" xmlUrl="https://blog.golang.org/feed.atom"/>
+		<outline text="News">
+			<outline text="Heise" type="rss" xmlUrl="http://www.heise.de/developer/rss/news-atom.xml"/>
+			<outline text="Local">
+				<outline text="Caf` + "\xe9" + `" type="rss" xmlUrl="http://example.org/feed"/>
+			</outline>
+		</outline>
+	</body>
+</opml>`
+
+type subpair struct {
+	Text    string
+	Folders []string
+}
+
+func TestParseOPML(t *testing.T) {
+	o, err := ParseOPML(strings.NewReader(testOPML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Head.Title != "Subscriptions" {
+		t.Fatalf("Expected %q - got %q", "Subscriptions", o.Head.Title)
+	}
+
+	expected := []subpair{
+		{"Go Blog", nil},
+		{"Heise", []string{"News"}},
+		{"Café", []string{"News", "Local"}},
+	}
+
+	subs := o.Subscriptions()
+	if len(subs) != len(expected) {
+		t.Fatalf("Expected %d subscriptions - got %d", len(expected), len(subs))
+	}
+
+	for i, sub := range subs {
+		if sub.Text != expected[i].Text {
+			t.Fatalf("Expected %q - got %q", expected[i].Text, sub.Text)
+		}
+		if !reflect.DeepEqual(sub.Folders, expected[i].Folders) {
+			t.Fatalf("Expected %v - got %v", expected[i].Folders, sub.Folders)
+		}
+	}
+}
+
+func TestWriteOPML(t *testing.T) {
+	feeds := []Feed{
+		{"", feedparser.Feed{Title: "Example", Link: "http://example.org/", Self: "http://example.org/feed"}},
+		{"http://example.com/rss", feedparser.Feed{Title: "Other", Link: "http://example.com/"}},
+	}
+
+	o, err := FromFeeds("Export", feeds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(o.Body.Outlines) != 2 || o.Body.Outlines[1].XMLURL != "http://example.com/rss" {
+		t.Fatalf("Expected both feeds to be exported - got %v", o.Body.Outlines)
+	}
+	o.Body.Outlines = append(o.Body.Outlines,
+		NewFolder("Folder", NewOutline(feeds[1].Feed, "http://example.com/atom")))
+
+	var buf bytes.Buffer
+	if err := WriteOPML(&buf, o); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseOPML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed.XMLName = o.XMLName
+	if !reflect.DeepEqual(parsed, o) {
+		t.Fatalf("Expected %v - got %v", o, parsed)
+	}
+
+	if _, err := FromFeeds("Export", []Feed{{"", feedparser.Feed{Title: "Unknown"}}}); err == nil {
+		t.Fatal("Expected error for feed without URL")
+	}
+}
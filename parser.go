@@ -0,0 +1,192 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Parser parses feeds according to its configuration. The zero value is
+// a valid Parser which behaves like the Parse function. A Parser must not
+// be modified once it is in use, it is safe for concurrent use by
+// multiple goroutines.
+type Parser struct {
+	// Order of the items in the parsed feed (optional).
+	Order Order
+
+	// Function used to order the items, overrides Order (optional).
+	Less func(a, b Item) bool
+
+	// Maximum size of the document in bytes (optional).
+	MaxSize int64
+
+	// Maximum number of items in the feed (optional).
+	MaxItems int
+
+	// Maximum nesting depth of XML elements (optional).
+	MaxDepth int
+
+	// Maximum length of the text of a single field of the feed or an
+	// item in bytes, including the text of nested elements, e.g. xhtml
+	// content. Text consisting only of whitespace is not counted
+	// (optional).
+	MaxTextLength int
+
+	// Whether to recover from malformed XML instead of failing, the
+	// applied repairs are reported as warnings (optional).
+	Lenient bool
+
+	// Charset used to decode all documents, overrides byte order marks
+	// and declared encodings (optional).
+	Charset string
+
+	// Policy used to select the primary link of feeds and items,
+	// defaults to DefaultLinkPolicy (optional).
+	LinkPolicy LinkPolicy
+}
+
+// DefaultParser is the Parser used by Parse.
+var DefaultParser = &Parser{}
+
+// parseState holds the state of a single parser invocation.
+type parseState struct {
+	ctx    context.Context
+	parser *Parser
+
+	// Whether the XML decoder should be lenient.
+	lenient bool
+
+	// Whether the document was already converted to UTF-8.
+	utf8 bool
+
+	// Warnings emitted so far.
+	warnings []Warning
+
+	// Most recently created XML decoder.
+	decoder *xml.Decoder
+
+	// Positions of elements by their indexed path.
+	positions map[string]Position
+
+	// Document whose positions are computed on demand, see position.
+	data []byte
+}
+
+// Parse tries to parse the content of the given reader and orders the
+// items of the resulting feed as configured.
+func (p *Parser) Parse(r io.Reader) (f Feed, err error) {
+	return p.ParseContext(context.Background(), r)
+}
+
+// ParseContext is like Parse but aborts parsing once the given context
+// is done. If the document exceeds one of the configured limits a
+// *LimitError is returned.
+func (p *Parser) ParseContext(ctx context.Context, r io.Reader) (f Feed, err error) {
+	return p.ParseContentType(ctx, r, "")
+}
+
+// ParseContentType is like ParseContext but additionally uses the
+// charset parameter of the given HTTP Content-Type header to decode the
+// document. The encoding is determined as follows: the Charset of the
+// parser, a byte order mark, the Content-Type charset, the encoding
+// declared in the XML declaration. Documents which are not valid UTF-8
+// and don't declare their encoding are assumed to be windows-1252.
+func (p *Parser) ParseContentType(ctx context.Context, r io.Reader, contentType string) (f Feed, err error) {
+	s := &parseState{ctx: ctx, parser: p}
+
+	data, err := s.readAll(r)
+	if err != nil {
+		return
+	}
+
+	data, err = s.toUTF8(data, contentType)
+	if err != nil {
+		return
+	}
+
+	f, err = s.parse(data)
+	if err != nil && p.Lenient && !s.fatal(err) {
+		f, err = s.recover(data, err)
+	}
+
+	if err != nil {
+		return
+	}
+	f.Warnings = s.warnings
+
+	if p.Less != nil {
+		f.SortFunc(p.Less)
+	} else {
+		f.Sort(p.Order)
+	}
+
+	return
+}
+
+// parse parses the given document using the feed parser for its root
+// element.
+func (s *parseState) parse(data []byte) (f Feed, err error) {
+	root, err := rootElement(s, data)
+	if err != nil {
+		err = s.wrap(err)
+		return
+	}
+
+	parse, ok := parsers[root.Local]
+	if !ok {
+		err = unknownFormat(root)
+		return
+	}
+
+	return parse(s, data)
+}
+
+// warn records a warning for the parsed feed.
+func (s *parseState) warn(format string, a ...interface{}) {
+	s.warnAt(Position{}, format, a...)
+}
+
+// warnAt records a warning for the given position of the document.
+func (s *parseState) warnAt(pos Position, format string, a ...interface{}) {
+	s.warnings = append(s.warnings, Warning{fmt.Sprintf(format, a...), pos})
+}
+
+// readAll reads the entire document from the given reader.
+func (s *parseState) readAll(r io.Reader) ([]byte, error) {
+	return ioutil.ReadAll(s.reader(r))
+}
+
+// reader wraps the given reader to abort reading once the context is
+// done or the document exceeds the maximum size.
+func (s *parseState) reader(r io.Reader) io.Reader {
+	r = &ctxReader{s.ctx, r}
+	if s.parser.MaxSize > 0 {
+		r = &sizeReader{r, s.parser.MaxSize, 0}
+	}
+
+	return r
+}
+
+// fatal reports whether the given error must not be recovered from.
+func (s *parseState) fatal(err error) bool {
+	if _, ok := err.(*LimitError); ok {
+		return true
+	}
+
+	return s.ctx.Err() != nil
+}
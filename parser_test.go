@@ -0,0 +1,214 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRss = `<?xml version="1.0"?>
+<rss version="2.0">
+<channel>
+	<title>Test</title>
+	<link>http://example.org/</link>
+	<description>Test feed</description>
+	<item>
+		<title>First</title>
+		<guid>1</guid>
+		<pubDate>Mon, 03 Aug 2015 10:00:00 GMT</pubDate>
+	</item>
+	<item>
+		<title>Second</title>
+		<guid>2</guid>
+		<pubDate>Wed, 05 Aug 2015 10:00:00 GMT</pubDate>
+	</item>
+	<item>
+		<title>Third</title>
+		<guid>3</guid>
+		<pubDate>Tue, 04 Aug 2015 10:00:00 GMT</pubDate>
+	</item>
+</channel>
+</rss>`
+
+type parserpair struct {
+	Parser *Parser
+	IDs    string
+}
+
+func TestParser(t *testing.T) {
+	tests := []parserpair{
+		{DefaultParser, "231"},
+		{&Parser{Order: DocumentOrder}, "123"},
+		{&Parser{Order: PublishedAsc}, "132"},
+		{&Parser{Less: func(a, b Item) bool { return a.Title > b.Title }}, "321"},
+	}
+
+	for _, test := range tests {
+		feed, err := test.Parser.Parse(strings.NewReader(testRss))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids string
+		for _, item := range feed.Items {
+			ids += item.ID
+		}
+
+		if ids != test.IDs {
+			t.Fatalf("Expected %q - got %q", test.IDs, ids)
+		}
+	}
+}
+
+type limitpair struct {
+	Parser *Parser
+	Limit  string
+}
+
+func TestParserLimits(t *testing.T) {
+	tests := []limitpair{
+		{&Parser{MaxSize: 64}, "MaxSize"},
+		{&Parser{MaxItems: 2}, "MaxItems"},
+		{&Parser{MaxDepth: 3}, "MaxDepth"},
+		{&Parser{MaxTextLength: 5}, "MaxTextLength"},
+	}
+
+	for _, test := range tests {
+		_, err := test.Parser.Parse(strings.NewReader(testRss))
+		lerr, ok := err.(*LimitError)
+		if !ok {
+			t.Fatalf("Expected *LimitError - got %v", err)
+		}
+
+		if lerr.Limit != test.Limit {
+			t.Fatalf("Expected %q - got %q", test.Limit, lerr.Limit)
+		}
+	}
+
+	_, err := (&Parser{MaxTextLength: 10}).Parse(strings.NewReader(`<rss version="2.0"><channel>
+		<title>aaaaaaaa<b/>aaaaaaaaa</title>
+	</channel></rss>`))
+	if lerr, ok := err.(*LimitError); !ok || lerr.Limit != "MaxTextLength" {
+		t.Fatalf("Expected MaxTextLength *LimitError - got %v", err)
+	}
+
+	content := `<feed xmlns="http://www.w3.org/2005/Atom"><entry><content type="xhtml"><div>` +
+		strings.Repeat("<p>aaaaaaaaaa</p>", 10) + `</div></content></entry></feed>`
+	_, err = (&Parser{MaxTextLength: 50}).Parse(strings.NewReader(content))
+	if lerr, ok := err.(*LimitError); !ok || lerr.Limit != "MaxTextLength" {
+		t.Fatalf("Expected MaxTextLength *LimitError - got %v", err)
+	}
+
+	parser := &Parser{MaxSize: 4096, MaxItems: 3, MaxDepth: 4, MaxTextLength: 32}
+	if _, err := parser.Parse(strings.NewReader(testRss)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParserInnerXML(t *testing.T) {
+	data := []byte(`<feed xmlns="http://www.w3.org/2005/Atom">
+		<title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><b>Hi</b></div></title>
+	</feed>`)
+
+	s := &parseState{ctx: context.Background(), parser: &Parser{MaxTextLength: 64}}
+	if _, err := rootElement(s, data); err != nil {
+		t.Fatal(err)
+	}
+
+	var feed AtomFeed
+	if err := s.unmarshal(data, &feed); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<div xmlns="http://www.w3.org/1999/xhtml"><b>Hi</b></div>`
+	if feed.Title.InnerXML != expected {
+		t.Fatalf("Expected %q - got %q", expected, feed.Title.InnerXML)
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ParseContext(ctx, strings.NewReader(testRss))
+	if err != context.Canceled {
+		t.Fatalf("Expected %v - got %v", context.Canceled, err)
+	}
+}
+
+type sourcepair struct {
+	Data   string
+	Source Source
+}
+
+func TestSource(t *testing.T) {
+	tests := []sourcepair{
+		{`<feed xmlns="http://www.w3.org/2005/Atom"><updated>2015-08-05T18:30:02Z</updated><entry>
+			<updated>2015-08-05T18:30:02Z</updated>
+			<source>
+				<id>urn:source</id>
+				<title>Source</title>
+				<updated>2015-08-04T10:00:00Z</updated>
+				<link rel="alternate" type="text/html" href="http://example.org/"/>
+				<link rel="self" href="http://example.org/atom.xml"/>
+			</source>
+		</entry></feed>`, Source{
+			ID:      "urn:source",
+			Title:   "Source",
+			Link:    "http://example.org/",
+			Self:    "http://example.org/atom.xml",
+			Updated: time.Date(2015, time.August, 4, 10, 0, 0, 0, time.UTC),
+		}},
+		{`<rss version="2.0"><channel><item>
+			<pubDate>Wed, 05 Aug 2015 10:00:00 GMT</pubDate>
+			<source url="http://example.org/rss.xml">Source</source>
+		</item></channel></rss>`, Source{
+			Title: "Source",
+			Self:  "http://example.org/rss.xml",
+		}},
+	}
+
+	for _, test := range tests {
+		feed, err := Parse(strings.NewReader(test.Data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		source := feed.Items[0].Source
+		if source == nil {
+			t.Fatalf("Expected source for %q", test.Data)
+		}
+
+		if !source.Updated.Equal(test.Source.Updated) {
+			t.Fatalf("Expected %v - got %v", test.Source.Updated, source.Updated)
+		}
+		source.Updated = test.Source.Updated
+
+		if *source != test.Source {
+			t.Fatalf("Expected %+v - got %+v", test.Source, *source)
+		}
+	}
+
+	feed, err := Parse(strings.NewReader(testRss))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Items[0].Source != nil {
+		t.Fatalf("Expected no source - got %+v", feed.Items[0].Source)
+	}
+}
@@ -0,0 +1,156 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxPositionDepth is the maximum depth of elements whose position is
+// recorded while decoding.
+const maxPositionDepth = 4
+
+// Position describes a position in a document. Positions of elements
+// refer to the end of their start tag.
+type Position struct {
+	// Byte offset in the UTF-8 encoded document.
+	Offset int64
+
+	// Line number, starting at 1. Zero if the position is unknown.
+	Line int
+
+	// Column in bytes, starting at 1.
+	Column int
+}
+
+func (p Position) String() string {
+	if p.Line == 0 {
+		return "unknown position"
+	}
+
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// ParseError describes an error at a specific position of a document.
+type ParseError struct {
+	// Location of the offending element, e.g. /feed/entry[2]/updated.
+	// Empty for syntax errors.
+	Path string
+
+	// Position of the offending element or syntax error.
+	Position Position
+
+	// Underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("feedparser: %s: %v", e.Position, e.Err)
+	}
+
+	return fmt.Sprintf("feedparser: %s (%s): %v", e.Path, e.Position, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// decoderPosition returns the current position of the given decoder.
+func decoderPosition(d *xml.Decoder) Position {
+	line, column := d.InputPos()
+	return Position{d.InputOffset(), line, column}
+}
+
+// position returns the position of the element with the given path.
+// If the element wasn't encountered, the position of its innermost
+// ancestor which was encountered is returned. Attributes are ignored.
+func (s *parseState) position(path string) Position {
+	if s.positions == nil && s.data != nil {
+		s.scanPositions()
+	}
+
+	key := indexPath(path)
+	for len(key) > 0 {
+		if pos, ok := s.positions[key]; ok {
+			return pos
+		}
+		key = key[:strings.LastIndex(key, "/")]
+	}
+
+	return Position{}
+}
+
+// scanPositions records the positions of the elements of the document.
+// Positions are only needed for errors and validation issues, hence they
+// are not recorded while parsing but by reading the document again.
+func (s *parseState) scanPositions() {
+	decoder := s.decoder
+	defer func() { s.decoder = decoder }()
+
+	r := s.limit(s.newDecoder(bytes.NewReader(s.data)), true)
+	for {
+		if _, err := r.Token(); err != nil {
+			return
+		}
+	}
+}
+
+// wrap annotates the given error with the current position of the
+// decoder. Errors caused by limits or the context are not annotated.
+func (s *parseState) wrap(err error) error {
+	if err == nil || s.fatal(err) || s.decoder == nil {
+		return err
+	}
+
+	if _, ok := err.(*ParseError); ok {
+		return err
+	}
+
+	return &ParseError{Position: decoderPosition(s.decoder), Err: err}
+}
+
+// parseTime is like the package-level parseTime but annotates errors
+// with the path and position of the element containing the date.
+func (s *parseState) parseTime(path, data string) (time.Time, error) {
+	date, err := parseTime(data)
+	if err != nil {
+		return date, &ParseError{path, s.position(path), err}
+	}
+
+	return date, nil
+}
+
+// indexPath converts the given path to a path where each element has an
+// explicit index, e.g. /rss/channel/item[2] becomes
+// /rss[1]/channel[1]/item[2]. Attribute components are removed.
+func indexPath(path string) string {
+	var b strings.Builder
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if len(name) == 0 || strings.HasPrefix(name, "@") {
+			break
+		}
+
+		b.WriteString("/" + name)
+		if !strings.HasSuffix(name, "]") {
+			b.WriteString("[1]")
+		}
+	}
+
+	return b.String()
+}
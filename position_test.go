@@ -0,0 +1,69 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"strings"
+	"testing"
+)
+
+type positionpair struct {
+	Data string
+	Path string
+	Line int
+}
+
+func TestParseError(t *testing.T) {
+	tests := []positionpair{
+		{"<feed xmlns=\"http://www.w3.org/2005/Atom\"><updated>2015-08-05T18:30:02Z</updated>\n<entry>\n<updated>yesterday</updated>\n</entry>\n</feed>",
+			"/feed/entry[1]/updated", 3},
+		{"<rss version=\"2.0\"><channel>\n<item>\n<pubDate>Mon, 03 Aug 2015 10:00:00 GMT</pubDate></item>\n<item>\n<pubDate>never</pubDate>\n</item>\n</channel></rss>",
+			"/rss/channel/item[2]/pubDate", 5},
+		{"<rss version=\"2.0\">\n<channel>\n<title>a</titel>\n</channel>\n</rss>", "", 3},
+		{"<feed xmlns=\"http://www.w3.org/2005/Atom\">\n\n<title>a &nbsp; b</title>\n</feed>", "", 3},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.Data))
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("Expected *ParseError - got %v", err)
+		}
+
+		if perr.Path != test.Path {
+			t.Fatalf("Expected path %q - got %q", test.Path, perr.Path)
+		}
+		if perr.Position.Line != test.Line {
+			t.Fatalf("Expected line %d - got %d (%v)", test.Line, perr.Position.Line, err)
+		}
+	}
+}
+
+func TestWarningPosition(t *testing.T) {
+	data := "<rss version=\"2.0\">\n<channel>\n<title>a & b</title>\n</channel>\n</rss>"
+
+	feed, err := (&Parser{Lenient: true}).Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, w := range feed.Warnings {
+		found = found || w.Position.Line == 3
+	}
+
+	if !found {
+		t.Fatalf("Expected warning for line 3 - got %v", feed.Warnings)
+	}
+}
@@ -0,0 +1,177 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+)
+
+// entityRegex matches a character or entity reference without the
+// leading ampersand.
+var entityRegex = regexp.MustCompile(`^(#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z_:][A-Za-z0-9_:.-]*);`)
+
+// recover attempts to parse a malformed document which couldn't be
+// parsed strictly. The given error is the one returned by the strict
+// parser.
+func (s *parseState) recover(data []byte, strictErr error) (f Feed, err error) {
+	s.lenient = true
+
+	var stripped int
+	if !isUTF16(data) {
+		data, stripped = stripInvalid(append([]byte(nil), data...))
+	}
+
+	f, err = s.parse(data)
+	if err != nil && !s.fatal(err) {
+		var escaped int
+		data, escaped = sanitize(data)
+
+		f, err = s.parse(data)
+		if err == nil {
+			s.warn("escaped %d stray markup characters", escaped)
+		}
+	}
+
+	if err != nil {
+		return
+	}
+
+	if stripped > 0 {
+		s.warn("removed %d invalid characters", stripped)
+	}
+	var pos Position
+	if perr, ok := strictErr.(*ParseError); ok {
+		pos, strictErr = perr.Position, perr.Err
+	}
+	s.warnAt(pos, "recovered from malformed XML: %v", strictErr)
+
+	return
+}
+
+// stripInvalid removes all control characters which are not allowed
+// in XML documents in place and returns the amount of removed
+// characters. The document must use an ASCII compatible encoding.
+func stripInvalid(data []byte) ([]byte, int) {
+	out := data[:0]
+	for _, b := range data {
+		if isValidByte(b) {
+			out = append(out, b)
+		}
+	}
+
+	return out, len(data) - len(out)
+}
+
+// isValidByte reports whether the given byte is allowed in an XML
+// document encoded using an ASCII compatible encoding.
+func isValidByte(b byte) bool {
+	return b >= 0x20 || b == '\t' || b == '\n' || b == '\r'
+}
+
+// isUTF16 reports whether the given document looks like it is encoded
+// using UTF-16.
+func isUTF16(data []byte) bool {
+	if len(data) < 2 {
+		return false
+	}
+
+	return data[0] == 0 || data[1] == 0 ||
+		(data[0] == 0xfe && data[1] == 0xff) ||
+		(data[0] == 0xff && data[1] == 0xfe)
+}
+
+// sanitize escapes ampersands and less-than signs which don't start a
+// reference or markup and removes content preceding the first element.
+// It returns the sanitized document and the amount of escaped
+// characters.
+func sanitize(data []byte) ([]byte, int) {
+	var n int
+	var buf bytes.Buffer
+
+	if i := bytes.IndexByte(data, '<'); i > 0 {
+		data = data[i:]
+	}
+
+	for len(data) > 0 {
+		switch {
+		case bytes.HasPrefix(data, []byte("<![CDATA[")):
+			data = copyUntil(&buf, data, "]]>")
+		case bytes.HasPrefix(data, []byte("<!--")):
+			data = copyUntil(&buf, data, "-->")
+		case data[0] == '<' && (len(data) == 1 || !isMarkupStart(data[1])):
+			buf.WriteString("&lt;")
+			data = data[1:]
+			n++
+		case data[0] == '&' && !entityRegex.Match(data[1:]):
+			buf.WriteString("&amp;")
+			data = data[1:]
+			n++
+		default:
+			buf.WriteByte(data[0])
+			data = data[1:]
+		}
+	}
+
+	return buf.Bytes(), n
+}
+
+// copyUntil copies data up to and including the given terminator to
+// the buffer and returns the remaining data.
+func copyUntil(buf *bytes.Buffer, data []byte, term string) []byte {
+	i := bytes.Index(data, []byte(term))
+	if i == -1 {
+		i = len(data)
+	} else {
+		i += len(term)
+	}
+
+	buf.Write(data[:i])
+	return data[i:]
+}
+
+// isMarkupStart reports whether the given byte, following a less-than
+// sign, starts a tag, declaration or processing instruction.
+func isMarkupStart(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') ||
+		b == '_' || b == ':' || b == '/' || b == '!' || b == '?' ||
+		b >= 0x80
+}
+
+// validReader is an io.Reader which removes all control characters that
+// are not allowed in XML documents, unless the document looks like it
+// is encoded using UTF-16.
+type validReader struct {
+	r io.Reader
+
+	// Whether the encoding was checked and UTF-16 was detected.
+	checked bool
+	utf16   bool
+}
+
+func (v *validReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	if !v.checked && n > 0 {
+		v.checked = true
+		v.utf16 = isUTF16(p[:n])
+	}
+
+	if v.utf16 {
+		return n, err
+	}
+
+	out, _ := stripInvalid(p[:n])
+	return len(out), err
+}
@@ -0,0 +1,63 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"strings"
+	"testing"
+)
+
+type recoverpair struct {
+	Data  string
+	Title string
+}
+
+func TestLenient(t *testing.T) {
+	tests := []recoverpair{
+		{"<title>Tom & Jerry</title>", "Tom & Jerry"},
+		{"<title>Caf&eacute;&nbsp;Bar</title>", "Café Bar"},
+		{"<title>Null\x00Byte\x0b</title>", "NullByte"},
+		{"<title>1 < 2</title>", "1 < 2"},
+		{"<title>Unclosed <b>Bold</title>", "Unclosed "},
+	}
+
+	parser := &Parser{Lenient: true}
+	for _, test := range tests {
+		data := "<rss><channel>" + test.Data + "<link>http://example.org/</link>" +
+			"<item><link>http://example.org/1</link><pubDate>Wed, 05 Aug 2015 10:00:00 GMT</pubDate></item>" +
+			"<item><link>http://example.org/2</link><pubDate>Thu, 06 Aug 2015 10:00:00 GMT</pubDate></item>" +
+			"</channel></rss>"
+		if _, err := Parse(strings.NewReader(data)); err == nil {
+			t.Fatalf("Expected strict parser to fail on %q", test.Data)
+		}
+
+		feed, err := parser.Parse(strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if feed.Title != test.Title {
+			t.Fatalf("Expected %q - got %q", test.Title, feed.Title)
+		}
+		if feed.Link != "http://example.org/" {
+			t.Fatalf("Expected link %q - got %q", "http://example.org/", feed.Link)
+		}
+		if len(feed.Items) != 2 || feed.Items[1].Link != "http://example.org/1" {
+			t.Fatalf("Expected two items - got %v", feed.Items)
+		}
+		if len(feed.Warnings) == 0 {
+			t.Fatalf("Expected warnings for %q", test.Data)
+		}
+	}
+}
@@ -20,6 +20,9 @@ package feedparser
 
 import (
 	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
 )
 
 // RssFeed represents an rss web feed.
@@ -30,6 +33,10 @@ type RssFeed struct {
 	// Name of the channel (required).
 	Title string `xml:"channel>title"`
 
+	// Atom links of the channel, e.g. to the feed itself (optional). This
+	// field must precede Link as the first matching field is used.
+	AtomLinks []AtomLink `xml:"http://www.w3.org/2005/Atom channel>link"`
+
 	// URL to the website (required).
 	Link string `xml:"channel>link"`
 
@@ -57,9 +64,16 @@ type RssFeed struct {
 	// Last time the content was updated (optional).
 	LastBuildDate string `xml:"channel>lastBuildDate"`
 
+	// iTunes categories of the channel (optional). This field must
+	// precede Categories as the first matching field is used.
+	ItunesCategories []ItunesCategory `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd channel>category"`
+
 	// Categories the feed belongs to (optional).
 	Categories []RssCategory `xml:"channel>category"`
 
+	// Dublin Core subjects of the channel (optional).
+	Subjects []string `xml:"http://purl.org/dc/elements/1.1/ channel>subject"`
+
 	// Program used to generate the channel (optional).
 	Generator string `xml:"channel>generator"`
 
@@ -72,6 +86,10 @@ type RssFeed struct {
 	// How long the channel can be cached (optional).
 	TTL int `xml:"channel>ttl"`
 
+	// iTunes artwork of the channel (optional). This field must precede
+	// Image as the first matching field is used.
+	ItunesImage ItunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd channel>image"`
+
 	// Image that can be displayed with the channel (optional).
 	Image RssImage `xml:"channel>image"`
 
@@ -90,12 +108,19 @@ type RssFeed struct {
 
 // RssItem represents an rss item.
 type RssItem struct {
+	// Media RSS and iTunes elements of the item (optional).
+	Media
+
 	// Title of the item (required if description isn't present).
 	Title string `xml:"title"`
 
 	// The item synopsis (required if title isn't present).
 	Description string `xml:"description"`
 
+	// Atom links of the item (optional). This field must precede Link
+	// as the first matching field is used.
+	AtomLinks []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+
 	// The URL of the item (optional).
 	Link string `xml:"link"`
 
@@ -105,6 +130,9 @@ type RssItem struct {
 	// Includes item in one or more categories (optional).
 	Categories []RssCategory `xml:"category"`
 
+	// Dublin Core subjects of the item (optional).
+	Subjects []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+
 	// URL to a page for comments (optional).
 	Comments string `xml:"comments"`
 
@@ -112,7 +140,7 @@ type RssItem struct {
 	Enclosure RssEnclosure `xml:"enclosure"`
 
 	// String that uniquely identifies the item (optional).
-	GUID string `xml:"guid"`
+	GUID RssGUID `xml:"guid"`
 
 	// Time the item was published (optional).
 	PubDate string `xml:"pubDate"`
@@ -121,6 +149,21 @@ type RssItem struct {
 	Source RssSource `xml:"source"`
 }
 
+// RssGUID represents the rss guid tag.
+type RssGUID struct {
+	// String that uniquely identifies the item (required).
+	Value string `xml:",chardata"`
+
+	// Whether the value is a permanent URL of the item, defaults to true
+	// (optional).
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+
+// PermaLink reports whether the guid is a permanent URL of the item.
+func (g RssGUID) PermaLink() bool {
+	return len(g.Value) > 0 && !strings.EqualFold(strings.TrimSpace(g.IsPermaLink), "false")
+}
+
 // RssEnclosure represents an rss enclosure.
 type RssEnclosure struct {
 	// Where the enclosure is located (required).
@@ -218,55 +261,134 @@ type RssDay struct {
 }
 
 // parseRss parses an rss feed and returns a generic feed.
-func parseRss(data []byte) (f Feed, err error) {
+func parseRss(s *parseState, data []byte) (f Feed, err error) {
 	var origFeed RssFeed
-	if err = unmarshal(data, &origFeed); err != nil {
+	if err = s.unmarshal(data, &origFeed); err != nil {
 		return
 	}
 
+	f, err = rssFeed(s, &origFeed)
+	if err != nil {
+		return
+	}
+
+	for i := range origFeed.Items {
+		var item Item
+		item, err = rssItem(s, &origFeed.Items[i], i)
+		if err != nil {
+			return
+		}
+
+		f.Items = append(f.Items, item)
+	}
+
+	return
+}
+
+// rssFeed converts the metadata of an rss feed to a generic feed
+// without items.
+func rssFeed(s *parseState, origFeed *RssFeed) (f Feed, err error) {
 	f = Feed{
 		Type:        "rss",
 		Title:       origFeed.Title,
-		Link:        origFeed.Link,
 		Description: origFeed.Description,
-		Image:       origFeed.Image.URL,
 		Generator:   origFeed.Generator,
 		Rights:      origFeed.Copyright,
 		Author:      origFeed.Editor,
+		Self:        findRel(atomLinks(origFeed.AtomLinks), "self").Href,
+		Logo: FeedImage{
+			URL:    origFeed.Image.URL,
+			Title:  origFeed.Image.Title,
+			Link:   origFeed.Image.Link,
+			Width:  origFeed.Image.Width,
+			Height: origFeed.Image.Height,
+		},
 	}
 
+	if len(strings.TrimSpace(origFeed.Link)) > 0 {
+		f.Links = append(f.Links, newLink(origFeed.Link, "alternate", "", "", "", ""))
+	}
+	f.Links = append(f.Links, atomLinks(origFeed.AtomLinks)...)
+	f.Link = s.primaryLink(f.Links).Href
+
+	feedImages(&f, origFeed.ItunesImage.Href)
+
 	if len(origFeed.LastBuildDate) > 0 {
-		f.Updated, err = parseTime(origFeed.LastBuildDate)
+		f.Updated, err = s.parseTime("/rss/channel/lastBuildDate", origFeed.LastBuildDate)
 		if err != nil {
 			return
 		}
 	}
 
+	var categories categories
 	for _, category := range origFeed.Categories {
-		f.Categories = append(f.Categories, category.Name)
+		categories.add(category.Name, category.Domain, "")
 	}
+	for _, subject := range origFeed.Subjects {
+		categories.add(subject, "", "")
+	}
+	categories.addItunes("", origFeed.ItunesCategories)
+	f.Categories, f.CategoryDetails = categories.terms(), categories
 
-	for _, entry := range origFeed.Items {
-		item := Item{
-			ID:         entry.GUID,
-			Title:      entry.Title,
-			Link:       entry.Link,
-			Content:    entry.Description,
-			Attachment: entry.Enclosure.URL,
-			Author:     entry.Author,
-		}
+	return
+}
 
-		for _, category := range entry.Categories {
-			item.Categories = append(item.Categories, category.Name)
-		}
+// rssItem converts an rss item, which is located at the given position
+// in the document, to a generic item.
+func rssItem(s *parseState, entry *RssItem, index int) (item Item, err error) {
+	item = Item{
+		ID:          entry.GUID.Value,
+		IsPermaLink: entry.GUID.PermaLink(),
+		Title:       entry.Title,
+		Content:     entry.Description,
+		Attachment:  entry.Enclosure.URL,
+		Author:      entry.Author,
+		Index:       index,
+	}
 
-		item.PubDate, err = parseTime(entry.PubDate)
-		if err != nil {
-			return
+	var categories categories
+	for _, category := range entry.Categories {
+		categories.add(category.Name, category.Domain, "")
+	}
+	for _, subject := range entry.Subjects {
+		categories.add(subject, "", "")
+	}
+	item.Categories, item.CategoryDetails = categories.terms(), categories
+
+	if len(entry.Source.URL) > 0 || len(entry.Source.Name) > 0 {
+		item.Source = &Source{Title: entry.Source.Name, Self: entry.Source.URL}
+	}
+
+	if len(strings.TrimSpace(entry.Link)) > 0 {
+		item.Links = append(item.Links, newLink(entry.Link, "alternate", "", "", "", ""))
+	} else if item.IsPermaLink {
+		// Many feeds only provide the URL of an item as its guid.
+		if u, err := url.Parse(strings.TrimSpace(item.ID)); err == nil && u.IsAbs() {
+			item.Links = append(item.Links, newLink(u.String(), "alternate", "", "", "", ""))
 		}
+	}
 
-		f.Items = append(f.Items, item)
+	item.Links = append(item.Links, atomLinks(entry.AtomLinks)...)
+	if len(entry.Enclosure.URL) > 0 {
+		item.Links = append(item.Links, newLink(entry.Enclosure.URL, "enclosure",
+			entry.Enclosure.Type, "", "", entry.Enclosure.Length))
 	}
+	if len(entry.Comments) > 0 {
+		item.Links = append(item.Links, newLink(entry.Comments, "replies", "", "", "", ""))
+	}
+	item.Link = s.primaryLink(item.Links).Href
+
+	enclosures := []enclosure{{entry.Enclosure.URL, entry.Enclosure.Type}}
+	item.Image = findImage(item.Link, &entry.Media, enclosures, item.Content)
+
+	path := fmt.Sprintf("/rss/channel/item[%d]", index+1)
+	item.PubDate, err = s.parseTime(path+"/pubDate", entry.PubDate)
+	if err != nil {
+		return
+	}
+
+	// RSS doesn't track item updates.
+	item.Updated = item.PubDate
 
 	return
 }
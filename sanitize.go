@@ -0,0 +1,279 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"strings"
+)
+
+// droppedElements lists elements which are removed by the sanitizer
+// including their content unless they are allowed by the policy, all
+// other elements which aren't allowed are replaced by their content.
+var droppedElements = []string{
+	"applet",
+	"base",
+	"embed",
+	"frame",
+	"frameset",
+	"head",
+	"iframe",
+	"link",
+	"math",
+	"meta",
+	"noembed",
+	"noframes",
+	"noscript",
+	"object",
+	"plaintext",
+	"script",
+	"select",
+	"style",
+	"svg",
+	"template",
+	"textarea",
+	"title",
+	"xmp",
+}
+
+// urlAttributes lists attributes which contain URLs.
+var urlAttributes = []string{
+	"action",
+	"background",
+	"cite",
+	"codebase",
+	"data",
+	"dynsrc",
+	"formaction",
+	"href",
+	"icon",
+	"longdesc",
+	"lowsrc",
+	"manifest",
+	"poster",
+	"src",
+}
+
+// Policy describes which HTML elements, attributes and URL schemes are
+// retained by the sanitizer. A Policy must not be modified once it is in
+// use, it is safe for concurrent use by multiple goroutines.
+type Policy struct {
+	// Allowed elements mapped to their allowed attributes. Elements
+	// like iframe can be allowed as well, the content of allowed raw
+	// text elements like script is escaped. SVG and MathML elements
+	// are always removed.
+	Elements map[string][]string
+
+	// Attributes allowed on all allowed elements (optional).
+	GlobalAttributes []string
+
+	// Allowed schemes of URL attributes, e.g. href. Relative URLs are
+	// always allowed (optional).
+	URLSchemes []string
+}
+
+// DefaultPolicy is the Policy used by Item.SanitizedContent, it allows
+// common formatting, links, images, tables and media elements but no
+// scripts, styles, forms or frames.
+var DefaultPolicy = &Policy{
+	Elements: map[string][]string{
+		"a":          {"href"},
+		"abbr":       nil,
+		"audio":      {"src", "controls"},
+		"b":          nil,
+		"blockquote": {"cite"},
+		"br":         nil,
+		"caption":    nil,
+		"cite":       nil,
+		"code":       nil,
+		"dd":         nil,
+		"del":        {"cite", "datetime"},
+		"details":    nil,
+		"dfn":        nil,
+		"div":        nil,
+		"dl":         nil,
+		"dt":         nil,
+		"em":         nil,
+		"figcaption": nil,
+		"figure":     nil,
+		"h1":         nil,
+		"h2":         nil,
+		"h3":         nil,
+		"h4":         nil,
+		"h5":         nil,
+		"h6":         nil,
+		"hr":         nil,
+		"i":          nil,
+		"img":        {"src", "alt", "width", "height"},
+		"ins":        {"cite", "datetime"},
+		"kbd":        nil,
+		"li":         nil,
+		"mark":       nil,
+		"ol":         {"start", "reversed"},
+		"p":          nil,
+		"pre":        nil,
+		"q":          {"cite"},
+		"s":          nil,
+		"samp":       nil,
+		"small":      nil,
+		"source":     {"src", "type"},
+		"span":       nil,
+		"strike":     nil,
+		"strong":     nil,
+		"sub":        nil,
+		"summary":    nil,
+		"sup":        nil,
+		"table":      nil,
+		"tbody":      nil,
+		"td":         {"colspan", "rowspan"},
+		"tfoot":      nil,
+		"th":         {"colspan", "rowspan", "scope"},
+		"thead":      nil,
+		"time":       {"datetime"},
+		"tr":         nil,
+		"u":          nil,
+		"ul":         nil,
+		"var":        nil,
+		"video":      {"src", "controls", "poster", "width", "height"},
+	},
+	GlobalAttributes: []string{"title", "lang", "dir"},
+	URLSchemes:       []string{"http", "https", "mailto"},
+}
+
+// SanitizedContent returns the content of the item sanitized using the
+// DefaultPolicy, which makes it safe to embed in HTML documents.
+func (i Item) SanitizedContent() string {
+	return DefaultPolicy.Sanitize(i.Content)
+}
+
+// Sanitize parses the given HTML fragment and returns it with all
+// elements and attributes which aren't allowed by the policy removed.
+// Comments are removed as well and URL attributes are removed unless
+// they are relative or use an allowed scheme.
+func (p *Policy) Sanitize(content string) string {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+
+	nodes, err := html.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		// Only returned if reading fails, which can't happen here.
+		return ""
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		p.render(&b, n)
+	}
+
+	return b.String()
+}
+
+// render writes the sanitized HTML for the given node to the builder.
+func (p *Policy) render(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	attrs, ok := p.Elements[n.Data]
+	if len(n.Namespace) > 0 || (!ok && contains(droppedElements, n.Data)) {
+		return
+	}
+
+	if ok {
+		b.WriteString("<" + n.Data)
+		for _, a := range n.Attr {
+			if p.allowAttr(attrs, a) {
+				b.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+			}
+		}
+		b.WriteString(">")
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.render(b, c)
+	}
+
+	if ok && !isVoidElement(n.Data) {
+		b.WriteString("</" + n.Data + ">")
+	}
+}
+
+// allowAttr reports whether the given attribute is allowed on an element
+// with the given allowed attributes.
+func (p *Policy) allowAttr(attrs []string, a html.Attribute) bool {
+	if len(a.Namespace) > 0 {
+		return false
+	} else if !contains(attrs, a.Key) && !contains(p.GlobalAttributes, a.Key) {
+		return false
+	}
+
+	switch {
+	case a.Key == "srcset":
+		// Comma separated candidates consisting of a URL and an
+		// optional descriptor.
+		for _, candidate := range strings.Split(a.Val, ",") {
+			fields := strings.Fields(candidate)
+			if len(fields) > 0 && !p.allowURL(fields[0]) {
+				return false
+			}
+		}
+	case a.Key == "ping":
+		for _, u := range strings.Fields(a.Val) {
+			if !p.allowURL(u) {
+				return false
+			}
+		}
+	case contains(urlAttributes, a.Key):
+		return p.allowURL(a.Val)
+	}
+
+	return true
+}
+
+// allowURL reports whether the given URL is relative or uses one of the
+// allowed schemes. Browsers ignore whitespace and control characters in
+// URLs, these are thus removed before determining the scheme.
+func (p *Policy) allowURL(rawurl string) bool {
+	rawurl = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, rawurl)
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return false
+	}
+
+	return len(u.Scheme) == 0 || contains(p.URLSchemes, strings.ToLower(u.Scheme))
+}
+
+// isVoidElement reports whether the element with the given name can't
+// have any content and thus has no end tag.
+func isVoidElement(name string) bool {
+	switch name {
+	case "area", "base", "br", "col", "embed", "hr", "img", "input",
+		"link", "meta", "param", "source", "track", "wbr":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,202 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"testing"
+)
+
+type sanitizepair struct {
+	Content   string
+	Sanitized string
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []sanitizepair{
+		{"<script>alert(1)</script>",
+			""},
+		{"<SCRIPT SRC=http://xss.rocks/xss.js></SCRIPT>",
+			""},
+		{"<p>Hello <b>World</b></p>",
+			"<p>Hello <b>World</b></p>"},
+		{"<IMG SRC=\"javascript:alert('XSS');\">",
+			"<img>"},
+		{"<IMG SRC=javascript:alert('XSS')>",
+			"<img>"},
+		{"<IMG SRC=JaVaScRiPt:alert('XSS')>",
+			"<img>"},
+		{"<IMG SRC=`javascript:alert(\"RSnake says, 'XSS'\")`>",
+			"<img>"},
+		{"<a onmouseover=\"alert(document.cookie)\">xxs link</a>",
+			"<a>xxs link</a>"},
+		{"<IMG \"\"\"><SCRIPT>alert(\"XSS\")</SCRIPT>\">",
+			"<img>&#34;&gt;"},
+		{"<img src=x onerror=alert(1)>",
+			"<img src=\"x\">"},
+		{"<IMG SRC=/ onerror=\"alert(String.fromCharCode(88,83,83))\"></img>",
+			"<img src=\"/\">"},
+		{"<IMG SRC=&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;&#97;&#108;&#101;&#114;&#116;&#40;&#39;&#88;&#83;&#83;&#39;&#41;>",
+			"<img>"},
+		{"<IMG SRC=&#x6A&#x61&#x76&#x61&#x73&#x63&#x72&#x69&#x70&#x74&#x3A&#x61&#x6C&#x65&#x72&#x74&#x28&#x27&#x58&#x53&#x53&#x27&#x29>",
+			"<img>"},
+		{"<IMG SRC=\"jav\tascript:alert('XSS');\">",
+			"<img>"},
+		{"<IMG SRC=\"jav&#x09;ascript:alert('XSS');\">",
+			"<img>"},
+		{"<IMG SRC=\"jav&#x0A;ascript:alert('XSS');\">",
+			"<img>"},
+		{"<IMG SRC=\" &#14;  javascript:alert('XSS');\">",
+			"<img>"},
+		{"<SCRIPT/XSS SRC=\"http://xss.rocks/xss.js\"></SCRIPT>",
+			""},
+		{"<BODY onload!#$%&()*~+-_.,:;?@[/|\\]^`=alert(\"XSS\")>",
+			""},
+		{"<<SCRIPT>alert(\"XSS\");//<</SCRIPT>",
+			"&lt;"},
+		{"<SCRIPT SRC=http://xss.rocks/xss.js?< B >",
+			""},
+		{"<iframe src=http://xss.rocks/scriptlet.html <",
+			""},
+		{"</TITLE><SCRIPT>alert(\"XSS\");</SCRIPT>",
+			""},
+		{"<INPUT TYPE=\"IMAGE\" SRC=\"javascript:alert('XSS');\">",
+			""},
+		{"<BODY BACKGROUND=\"javascript:alert('XSS')\">",
+			""},
+		{"<svg/onload=alert('XSS')>",
+			""},
+		{"<svg><script>alert(1)</script></svg>",
+			""},
+		{"<math><mi xlink:href=\"javascript:alert(1)\">x</mi></math>",
+			""},
+		{"<STYLE>li {list-style-image: url(\"javascript:alert('XSS')\");}</STYLE><UL><LI>XSS</br>",
+			"<ul><li>XSS<br></li></ul>"},
+		{"<div style=\"width: expression(alert('XSS'));\">x</div>",
+			"<div>x</div>"},
+		{"<LINK REL=\"stylesheet\" HREF=\"javascript:alert('XSS');\">",
+			""},
+		{"<META HTTP-EQUIV=\"refresh\" CONTENT=\"0;url=javascript:alert('XSS');\">",
+			""},
+		{"<TABLE BACKGROUND=\"javascript:alert('XSS')\"><tr><td>x</td></tr></TABLE>",
+			"<table><tbody><tr><td>x</td></tr></tbody></table>"},
+		{"<OBJECT TYPE=\"text/x-scriptlet\" DATA=\"http://xss.rocks/scriptlet.html\"></OBJECT>",
+			""},
+		{"<EMBED SRC=\"data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcv\">",
+			""},
+		{"<a href=\"data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==\">x</a>",
+			"<a>x</a>"},
+		{"<a href=\"vbscript:msgbox(1)\">x</a>",
+			"<a>x</a>"},
+		{"<a href=\"http://example.org/?a=1&b=2\" target=\"_blank\" rel=\"nofollow\">ok</a>",
+			"<a href=\"http://example.org/?a=1&amp;b=2\">ok</a>"},
+		{"<a href=\"/relative\" title=\"t\">rel</a>",
+			"<a href=\"/relative\" title=\"t\">rel</a>"},
+		{"<a href=\"mailto:a@example.org\">mail</a>",
+			"<a href=\"mailto:a@example.org\">mail</a>"},
+		{"<!--<script>alert(1)</script>-->x",
+			"x"},
+		{"<img src=\"http://example.org/a.png\" alt=\"a&quot; onerror=&quot;alert(1)\">",
+			"<img src=\"http://example.org/a.png\" alt=\"a&#34; onerror=&#34;alert(1)\">"},
+		{"<form action=\"javascript:alert(1)\"><button formaction=\"javascript:alert(1)\">x</button></form>",
+			"x"},
+		{"<noscript><p title=\"</noscript><img src=x onerror=alert(1)>\"></noscript>",
+			"<img src=\"x\">&#34;&gt;"},
+		{"<details open ontoggle=alert(1)><summary>s</summary>d</details>",
+			"<details><summary>s</summary>d</details>"},
+		{"<video poster=javascript:alert(1)><source src=\"http://example.org/a.mp4\" type=\"video/mp4\"></video>",
+			"<video><source src=\"http://example.org/a.mp4\" type=\"video/mp4\"></video>"},
+		{"<p>a &lt;script&gt; b</p>",
+			"<p>a &lt;script&gt; b</p>"},
+		{"<a href=\"&#1;javascript:alert(1)\">x</a>",
+			"<a>x</a>"},
+		{"<a href=\"java&#0000115;cript:alert(1)\">x</a>",
+			"<a>x</a>"},
+		{"<a href=\"javascript&colon;alert(1)\">x</a>",
+			"<a>x</a>"},
+		{"<blockquote cite=\"javascript:alert(1)\">q</blockquote>",
+			"<blockquote>q</blockquote>"},
+		{"<x onclick=alert(1)>custom</x>",
+			"custom"},
+		{"<template><script>alert(1)</script></template>after",
+			"after"},
+		{"<textarea><script>alert(1)</script></textarea>",
+			""},
+		{"<a href=\"  HTTPS://example.org\">x</a>",
+			"<a href=\"  HTTPS://example.org\">x</a>"},
+		{"<base href=\"javascript:alert(1)//\">",
+			""},
+		{"<img src=\"x:alert(1)\">",
+			"<img>"},
+		{"<img src=\"//example.org/a.png\">",
+			"<img src=\"//example.org/a.png\">"},
+		{"<ol start=3 onclick=x><li>a</ol>",
+			"<ol start=\"3\"><li>a</li></ol>"},
+	}
+
+	for _, test := range tests {
+		item := Item{Content: test.Content}
+		if sanitized := item.SanitizedContent(); sanitized != test.Sanitized {
+			t.Fatalf("Expected %q - got %q", test.Sanitized, sanitized)
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	policy := &Policy{
+		Elements: map[string][]string{
+			"a":      {"href", "rel", "ping"},
+			"p":      nil,
+			"iframe": {"src"},
+			"svg":    nil,
+			"object": {"data"},
+		},
+		URLSchemes: []string{"https"},
+	}
+
+	tests := []sanitizepair{
+		{`<p title="t">a</p><b>b</b>`, `<p>a</p>b`},
+		{`<a href="https://example.org" rel="nofollow">x</a>`, `<a href="https://example.org" rel="nofollow">x</a>`},
+		{`<a href="http://example.org">x</a>`, `<a>x</a>`},
+		{`<a href="mailto:a@example.org">x</a>`, `<a>x</a>`},
+		{`<img src="https://example.org/a.png">`, ``},
+		{`<script>alert(1)</script>`, ``},
+		{`<iframe src="https://www.youtube.com/embed/x" onload="alert(1)"></iframe>`, `<iframe src="https://www.youtube.com/embed/x"></iframe>`},
+		{`<iframe src="javascript:alert(1)"><script>alert(1)</script></iframe>`, `<iframe>&lt;script&gt;alert(1)&lt;/script&gt;</iframe>`},
+		{`<svg><script>alert(1)</script></svg>`, ``},
+		{`<object data="javascript:alert(1)"></object>`, `<object></object>`},
+		{`<object data="https://example.org/a.swf"></object>`, `<object data="https://example.org/a.swf"></object>`},
+		{`<a ping="https://example.org/p javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a ping="https://example.org/p /q">x</a>`, `<a ping="https://example.org/p /q">x</a>`},
+	}
+
+	for _, test := range tests {
+		if sanitized := policy.Sanitize(test.Content); sanitized != test.Sanitized {
+			t.Fatalf("Expected %q - got %q", test.Sanitized, sanitized)
+		}
+	}
+
+	images := &Policy{Elements: map[string][]string{"img": {"srcset", "lowsrc"}}, URLSchemes: []string{"https"}}
+	for _, test := range []sanitizepair{
+		{`<img srcset="https://example.org/a.png 1x, javascript:alert(1) 2x">`, `<img>`},
+		{`<img srcset="https://example.org/a.png 1x,/b.png 2x" lowsrc="javascript:alert(1)">`, `<img srcset="https://example.org/a.png 1x,/b.png 2x">`},
+	} {
+		if sanitized := images.Sanitize(test.Content); sanitized != test.Sanitized {
+			t.Fatalf("Expected %q - got %q", test.Sanitized, sanitized)
+		}
+	}
+
+	if sanitized := (&Policy{}).Sanitize(`<p>a <b>b</b></p>`); sanitized != "a b" {
+		t.Fatalf("Expected %q - got %q", "a b", sanitized)
+	}
+}
@@ -13,6 +13,54 @@
 
 package feedparser
 
+import (
+	"sort"
+)
+
+// Order describes the order of the items in a feed.
+type Order int
+
+const (
+	// PublishedDesc orders items by publication date, most recent first.
+	PublishedDesc Order = iota
+
+	// PublishedAsc orders items by publication date, oldest first.
+	PublishedAsc
+
+	// UpdatedDesc orders items by update date, most recent first.
+	UpdatedDesc
+
+	// UpdatedAsc orders items by update date, oldest first.
+	UpdatedAsc
+
+	// DocumentOrder retains the order in which items appear in the feed.
+	DocumentOrder
+)
+
+// Sort sorts the items of the feed in the given order. Sorting is
+// stable, items which compare equal retain their document order.
+func (f *Feed) Sort(order Order) {
+	sort.Stable(byIndex(f.Items))
+
+	switch order {
+	case PublishedDesc:
+		sort.Stable(byDate(f.Items))
+	case PublishedAsc:
+		sort.Stable(sort.Reverse(byDate(f.Items)))
+	case UpdatedDesc:
+		sort.Stable(byUpdated(f.Items))
+	case UpdatedAsc:
+		sort.Stable(sort.Reverse(byUpdated(f.Items)))
+	}
+}
+
+// SortFunc sorts the items of the feed using the given less function.
+// Sorting is stable, items which compare equal retain their current
+// order.
+func (f *Feed) SortFunc(less func(a, b Item) bool) {
+	sort.Stable(byFunc{f.Items, less})
+}
+
 // byDate sorts a generic Item slice by the items date attribute thus
 // sorting the items by the date they were published. It implements the
 // sort.Interface interface.
@@ -29,3 +77,54 @@ func (b byDate) Swap(i, j int) {
 func (b byDate) Less(i, j int) bool {
 	return b[i].PubDate.After(b[j].PubDate)
 }
+
+// byUpdated sorts a generic Item slice by the date the items were last
+// updated. It implements the sort.Interface interface.
+type byUpdated []Item
+
+func (b byUpdated) Len() int {
+	return len(b)
+}
+
+func (b byUpdated) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
+
+func (b byUpdated) Less(i, j int) bool {
+	return b[i].Updated.After(b[j].Updated)
+}
+
+// byIndex sorts a generic Item slice by the position of the items in
+// the original document. It implements the sort.Interface interface.
+type byIndex []Item
+
+func (b byIndex) Len() int {
+	return len(b)
+}
+
+func (b byIndex) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
+
+func (b byIndex) Less(i, j int) bool {
+	return b[i].Index < b[j].Index
+}
+
+// byFunc sorts a generic Item slice using an arbitrary less function.
+// It implements the sort.Interface interface.
+type byFunc struct {
+	items []Item
+	less  func(a, b Item) bool
+}
+
+func (b byFunc) Len() int {
+	return len(b.items)
+}
+
+func (b byFunc) Swap(i, j int) {
+	b.items[i], b.items[j] = b.items[j], b.items[i]
+}
+
+func (b byFunc) Less(i, j int) bool {
+	return b.less(b.items[i], b.items[j])
+}
@@ -0,0 +1,60 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"testing"
+	"time"
+)
+
+type sortpair struct {
+	Order Order
+	IDs   string
+}
+
+func TestSort(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2015, time.August, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	items := []Item{
+		{ID: "a", PubDate: day(2), Updated: day(5), Index: 0},
+		{ID: "b", PubDate: day(1), Updated: day(1), Index: 1},
+		{ID: "c", PubDate: day(2), Updated: day(3), Index: 2},
+		{ID: "d", Index: 3},
+		{ID: "e", PubDate: day(3), Updated: day(3), Index: 4},
+	}
+
+	tests := []sortpair{
+		{PublishedDesc, "eacbd"},
+		{PublishedAsc, "dbace"},
+		{UpdatedDesc, "acebd"},
+		{UpdatedAsc, "dbcea"},
+		{DocumentOrder, "abcde"},
+	}
+
+	for _, test := range tests {
+		feed := Feed{Items: append([]Item(nil), items...)}
+		feed.Sort(test.Order)
+
+		var ids string
+		for _, item := range feed.Items {
+			ids += item.ID
+		}
+
+		if ids != test.IDs {
+			t.Fatalf("Expected %q - got %q", test.IDs, ids)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"fmt"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// wordsPerMinute is the reading speed assumed by Item.ReadingTime.
+const wordsPerMinute = 200
+
+// ellipsis is appended to truncated texts.
+const ellipsis = "…"
+
+// blockElements lists elements which are separated from surrounding
+// text by a blank line.
+var blockElements = []string{
+	"address",
+	"article",
+	"aside",
+	"blockquote",
+	"details",
+	"dl",
+	"div",
+	"figure",
+	"footer",
+	"h1",
+	"h2",
+	"h3",
+	"h4",
+	"h5",
+	"h6",
+	"header",
+	"hr",
+	"ol",
+	"p",
+	"pre",
+	"section",
+	"table",
+	"ul",
+}
+
+// lineElements lists elements which start on a new line.
+var lineElements = []string{
+	"caption",
+	"dd",
+	"dt",
+	"figcaption",
+	"li",
+	"summary",
+	"tr",
+}
+
+// textWriter converts HTML to plain text.
+type textWriter struct {
+	b strings.Builder
+
+	// Whether link targets are collected as footnotes.
+	footnotes bool
+
+	// Link targets referenced by footnotes.
+	links []string
+
+	// Pending separator written before the next text.
+	space    bool
+	newlines int
+
+	// Nesting depth of lists and pre elements.
+	lists int
+	pre   int
+}
+
+// PlainText converts the given HTML fragment to plain text. Block
+// elements are separated by blank lines, list items are prefixed with
+// bullets and the targets of links are listed as numbered footnotes at
+// the end of the text.
+func PlainText(content string) string {
+	return plainText(content, true)
+}
+
+// plainText converts the given HTML fragment to plain text, optionally
+// with link footnotes.
+func plainText(content string, footnotes bool) string {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+
+	nodes, err := html.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		return ""
+	}
+
+	w := &textWriter{footnotes: footnotes}
+	for _, n := range nodes {
+		w.node(n)
+	}
+
+	if len(w.links) > 0 {
+		w.block(2)
+		for i, link := range w.links {
+			w.block(1)
+			w.write(fmt.Sprintf("[%d] %s", i+1, link))
+		}
+	}
+
+	return w.b.String()
+}
+
+// node writes the text of the given node and its descendants.
+func (w *textWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if len(n.Namespace) > 0 || contains(droppedElements, n.Data) {
+		return
+	}
+
+	if n.Data == "br" {
+		w.b.WriteString("\n")
+		w.space, w.newlines = false, 0
+		return
+	}
+	w.block(separation(n))
+
+	switch n.Data {
+	case "li":
+		w.write(strings.Repeat("  ", w.lists-1) + listMarker(n))
+		w.space = false
+	case "ol", "ul":
+		w.lists++
+		defer func() { w.lists-- }()
+	case "pre":
+		w.pre++
+		defer func() { w.pre-- }()
+	case "td", "th":
+		if n.PrevSibling != nil {
+			w.write("\t")
+			w.space = false
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+
+	if n.Data == "a" {
+		w.link(n)
+	}
+	w.block(separation(n))
+}
+
+// separation returns the amount of newlines separating the given element
+// from surrounding text.
+func separation(n *html.Node) int {
+	switch {
+	case (n.Data == "ol" || n.Data == "ul") && n.Parent != nil && n.Parent.Data == "li":
+		return 1
+	case contains(blockElements, n.Data):
+		return 2
+	case contains(lineElements, n.Data):
+		return 1
+	}
+
+	return 0
+}
+
+// text writes the given text, collapsing whitespace unless it is part
+// of a pre element.
+func (w *textWriter) text(text string) {
+	if w.pre > 0 {
+		w.write(text)
+		return
+	}
+
+	if len(text) > 0 && unicode.IsSpace(rune(text[0])) {
+		w.space = true
+	}
+
+	for i, word := range strings.Fields(text) {
+		if i > 0 {
+			w.space = true
+		}
+		w.write(word)
+	}
+
+	if len(text) > 0 && unicode.IsSpace(rune(text[len(text)-1])) {
+		w.space = true
+	}
+}
+
+// link adds a footnote for the link target of the given anchor.
+func (w *textWriter) link(n *html.Node) {
+	href := strings.TrimSpace(attribute(n, "href"))
+	if !w.footnotes || len(href) == 0 || strings.HasPrefix(href, "#") {
+		return
+	}
+
+	var text strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			text.WriteString(c.Data)
+		}
+	}
+	if strings.TrimSpace(text.String()) == href {
+		return
+	}
+
+	w.links = append(w.links, href)
+	w.write(fmt.Sprintf("[%d]", len(w.links)))
+}
+
+// block requests the given amount of newlines before the next text.
+func (w *textWriter) block(newlines int) {
+	if newlines > w.newlines {
+		w.newlines = newlines
+	}
+}
+
+// write writes the given text after the pending separator. Separators
+// at the beginning of the text are omitted.
+func (w *textWriter) write(text string) {
+	if w.b.Len() > 0 {
+		if w.newlines > 0 {
+			w.b.WriteString(strings.Repeat("\n", w.newlines))
+		} else if w.space {
+			w.b.WriteString(" ")
+		}
+	}
+
+	w.space, w.newlines = false, 0
+	w.b.WriteString(text)
+}
+
+// listMarker returns the marker of the given list item.
+func listMarker(n *html.Node) string {
+	if n.Parent == nil || n.Parent.Data != "ol" {
+		return "• "
+	}
+
+	index := 1
+	for c := n.PrevSibling; c != nil; c = c.PrevSibling {
+		if c.Type == html.ElementNode && c.Data == "li" {
+			index++
+		}
+	}
+
+	return fmt.Sprintf("%d. ", index)
+}
+
+// attribute returns the value of the attribute with the given name.
+func attribute(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name && len(a.Namespace) == 0 {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// Truncate shortens the given text to at most n characters, including
+// the appended ellipsis. The text is cut at a word boundary unless its
+// first word is longer than n characters.
+func Truncate(text string, n int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= n {
+		return string(runes)
+	} else if n <= 0 {
+		return ""
+	}
+
+	cut := n - 1
+	for i := cut; i > 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), isTrailing) + ellipsis
+}
+
+// TruncateWords shortens the given text to at most n words, whitespace
+// is collapsed and an ellipsis is appended if words were removed.
+func TruncateWords(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	} else if n <= 0 {
+		return ""
+	}
+
+	return strings.TrimRightFunc(strings.Join(words[:n], " "), isTrailing) + ellipsis
+}
+
+// isTrailing reports whether the given rune should be removed from the
+// end of a truncated text.
+func isTrailing(r rune) bool {
+	return unicode.IsSpace(r) || (unicode.IsPunct(r) && !strings.ContainsRune(")]\"'”’", r))
+}
+
+// Text returns the content of the item as plain text, see PlainText. If
+// the item has no content, its summary is used instead.
+func (i Item) Text() string {
+	return PlainText(i.content())
+}
+
+// Excerpt returns the text of the item as a single paragraph without
+// link footnotes, truncated to at most n characters.
+func (i Item) Excerpt(n int) string {
+	text := strings.Join(strings.Fields(plainText(i.content(), false)), " ")
+	return Truncate(text, n)
+}
+
+// ReadingTime estimates the time needed to read the text of the item,
+// rounded up to full minutes.
+func (i Item) ReadingTime() time.Duration {
+	words := len(strings.Fields(plainText(i.content(), false)))
+	minutes := math.Ceil(float64(words) / wordsPerMinute)
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// content returns the content of the item or its summary if the item
+// has no content.
+func (i Item) content() string {
+	if len(strings.TrimSpace(i.Content)) == 0 {
+		return i.Summary
+	}
+
+	return i.Content
+}
@@ -0,0 +1,108 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type textpair struct {
+	Content string
+	Text    string
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []textpair{
+		{"Hello   <b>World</b>!", "Hello World!"},
+		{"<p>First\nparagraph</p><p>Second</p>", "First paragraph\n\nSecond"},
+		{"a<br>b<br/>c", "a\nb\nc"},
+		{"Fish &amp; Chips &lt;3 &eacute;", "Fish & Chips <3 é"},
+		{"<h1>Title</h1>Text<script>alert(1)</script><style>p {}</style>", "Title\n\nText"},
+		{"<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li></ul>", "• One\n• Two\n  • Nested"},
+		{"<p>List:</p><ol><li>One</li><li>Two</li></ol><p>End</p>", "List:\n\n1. One\n2. Two\n\nEnd"},
+		{"<pre>a\n  b</pre>", "a\n  b"},
+		{"<table><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr></table>", "A\tB\n1\t2"},
+		{`See <a href="http://example.org/a">this</a> and <a href="http://example.org/b">that</a>.`,
+			"See this[1] and that[2].\n\n[1] http://example.org/a\n[2] http://example.org/b"},
+		{`<a href="http://example.org">http://example.org</a> <a href="#top">top</a>`, "http://example.org top"},
+	}
+
+	for _, test := range tests {
+		if text := PlainText(test.Content); text != test.Text {
+			t.Fatalf("Expected %q - got %q", test.Text, text)
+		}
+	}
+}
+
+type truncatepair struct {
+	Text      string
+	N         int
+	Truncated string
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []truncatepair{
+		{"Hello World", 20, "Hello World"},
+		{"Hello World", 11, "Hello World"},
+		{"Hello World", 10, "Hello…"},
+		{"Hello, World", 10, "Hello…"},
+		{"Supercalifragilistic", 6, "Super…"},
+		{"Grüße aus Köln", 12, "Grüße aus…"},
+		{"Hello", 0, ""},
+	}
+
+	for _, test := range tests {
+		if truncated := Truncate(test.Text, test.N); truncated != test.Truncated {
+			t.Fatalf("Expected %q - got %q", test.Truncated, truncated)
+		}
+	}
+
+	tests = []truncatepair{
+		{"one  two\nthree", 3, "one two three"},
+		{"one, two, three", 2, "one, two…"},
+		{"one two three", 0, ""},
+	}
+
+	for _, test := range tests {
+		if truncated := TruncateWords(test.Text, test.N); truncated != test.Truncated {
+			t.Fatalf("Expected %q - got %q", test.Truncated, truncated)
+		}
+	}
+}
+
+func TestItemText(t *testing.T) {
+	item := Item{
+		Content: `<p>Read <a href="http://example.org">the article</a>.</p><p>Thanks!</p>`,
+		Summary: "Summary",
+	}
+
+	if text := item.Text(); text != "Read the article[1].\n\nThanks!\n\n[1] http://example.org" {
+		t.Fatalf("Unexpected text %q", text)
+	}
+	if excerpt := item.Excerpt(16); excerpt != "Read the…" {
+		t.Fatalf("Expected %q - got %q", "Read the…", excerpt)
+	}
+
+	item.Content = ""
+	if text := item.Text(); text != "Summary" {
+		t.Fatalf("Expected %q - got %q", "Summary", text)
+	}
+
+	item.Content = strings.Repeat("word ", 401)
+	if d := item.ReadingTime(); d != 3*time.Minute {
+		t.Fatalf("Expected %v - got %v", 3*time.Minute, d)
+	}
+}
@@ -21,7 +21,8 @@ package feedparser
 import (
 	"bytes"
 	"encoding/xml"
-	"golang.org/x/net/html/charset"
+	"fmt"
+	"io"
 	"time"
 )
 
@@ -200,13 +201,85 @@ var dateFormats = []string{
 	time.UnixDate,
 }
 
-// unmarshal unmarshals an xml document to the given interface.
-// It uses a custom charsetReader and therefore supports non-utf8
-// xml encodings.
-func unmarshal(data []byte, v interface{}) error {
-	decoder := xml.NewDecoder(bytes.NewReader(data))
-	decoder.CharsetReader = charset.NewReaderLabel
-	return decoder.Decode(v)
+// autoClose lists the HTML elements which are closed automatically in
+// lenient mode. It is xml.HTMLAutoClose without link, which is a regular
+// element in rss feeds.
+var autoClose = func() (elements []string) {
+	for _, name := range xml.HTMLAutoClose {
+		if name != "link" {
+			elements = append(elements, name)
+		}
+	}
+
+	return
+}()
+
+// newDecoder returns a decoder for the xml document read from the given
+// reader. It uses a custom charsetReader and therefore supports non-utf8
+// xml encodings. In lenient mode HTML entities and common mistakes are
+// accepted. The limits of the parser are not enforced, see limit.
+func (s *parseState) newDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = s.charsetReader()
+	if s.lenient {
+		decoder.Strict = false
+		decoder.AutoClose = autoClose
+		decoder.Entity = xml.HTMLEntity
+	}
+
+	s.decoder = decoder
+	return decoder
+}
+
+// unmarshal unmarshals an xml document to the given interface. Errors
+// are annotated with the position they occurred at. The limits of the
+// parser must have been enforced by rootElement beforehand, decoding
+// from a token reader enforcing them would leave ,innerxml fields
+// empty.
+func (s *parseState) unmarshal(data []byte, v interface{}) error {
+	return s.wrap(s.newDecoder(bytes.NewReader(data)).Decode(v))
+}
+
+// rootElement returns the name of the root element of a document. The
+// entire root element is read to enforce the limits of the parser and
+// to check the context, unless neither can fail. The document is
+// retained to compute positions of elements on demand.
+func rootElement(s *parseState, data []byte) (root xml.Name, err error) {
+	s.data, s.positions = data, nil
+	decoder := s.limit(s.newDecoder(bytes.NewReader(data)), false)
+	scan := s.ctx.Done() != nil || s.parser.MaxItems > 0 ||
+		s.parser.MaxDepth > 0 || s.parser.MaxTextLength > 0
+
+	var depth int
+	for {
+		var tok xml.Token
+		tok, err = decoder.Token()
+		if err != nil {
+			return
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				root = t.Name
+				if !scan {
+					return
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// unknownFormat returns an error for documents with the given root
+// element, which isn't the root element of any known feed format.
+func unknownFormat(root xml.Name) error {
+	return fmt.Errorf("feedparser: unknown feed format <%s>", root.Local)
 }
 
 // parseTime tries to parse the given string as a date by trying
@@ -0,0 +1,332 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rfc822Formats describes all date formats permitted by RFC 822 with
+// four digit years as recommended by the RSS specification.
+var rfc822Formats = func() (formats []string) {
+	for _, day := range []string{"Mon, ", ""} {
+		for _, date := range []string{"02 Jan 2006", "2 Jan 2006", "02 Jan 06", "2 Jan 06"} {
+			for _, clock := range []string{"15:04:05", "15:04"} {
+				for _, zone := range []string{"MST", "-0700"} {
+					formats = append(formats, day+date+" "+clock+" "+zone)
+				}
+			}
+		}
+	}
+
+	return
+}()
+
+// weekdays lists all valid values of the RSS day element.
+var weekdays = []string{
+	"Monday",
+	"Tuesday",
+	"Wednesday",
+	"Thursday",
+	"Friday",
+	"Saturday",
+	"Sunday",
+}
+
+// Severity describes the severity of a validation issue.
+type Severity int
+
+const (
+	// SeverityError indicates a violation of the specification.
+	SeverityError Severity = iota
+
+	// SeverityWarning indicates a likely mistake which doesn't violate
+	// the specification.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Issue represents a problem found by the validator.
+type Issue struct {
+	// Severity of the issue.
+	Severity Severity
+
+	// Location of the offending element, e.g. /rss/channel/item[2]/link.
+	Path string
+
+	// Human readable description of the issue.
+	Message string
+
+	// Position of the offending element, unknown for elements which
+	// are missing or nested too deeply.
+	Position Position
+}
+
+func (i Issue) String() string {
+	if i.Position.Line > 0 {
+		return fmt.Sprintf("%s: %s (%s): %s", i.Severity, i.Path, i.Position, i.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Path, i.Message)
+}
+
+// validator collects validation issues.
+type validator struct {
+	s      *parseState
+	issues []Issue
+}
+
+// Validate checks whether the given atom or rss document conforms to
+// the specification of its format and returns all issues found.
+func Validate(data []byte) []Issue {
+	s := &parseState{ctx: context.Background(), parser: DefaultParser}
+	v := &validator{s: s}
+
+	data, err := s.toUTF8(data, "")
+	if err != nil {
+		v.errorf("/", "%v", err)
+		return v.issues
+	}
+
+	root, err := rootElement(s, data)
+	if err != nil {
+		v.errorf("/", "%v", err)
+		return v.issues
+	}
+
+	switch root.Local {
+	case "feed":
+		var feed AtomFeed
+		if err := s.unmarshal(data, &feed); err != nil {
+			v.errorf("/feed", "%v", err)
+			break
+		}
+		v.atomFeed(&feed)
+	case "rss":
+		var skip rssSkip
+		if err := s.unmarshal(data, &skip); err == nil {
+			v.rssSkip(&skip)
+		}
+
+		var feed RssFeed
+		if err := s.unmarshal(data, &feed); err != nil {
+			v.errorf("/rss", "%v", err)
+			break
+		}
+		v.rssFeed(&feed)
+	default:
+		v.errorf("/"+root.Local, "%v", unknownFormat(root))
+	}
+
+	return v.issues
+}
+
+// rssSkip contains the values of the skipHours and skipDays elements of
+// an rss feed as strings, thus allowing to validate them.
+type rssSkip struct {
+	XMLName xml.Name `xml:"rss"`
+	Hours   []string `xml:"channel>skipHours>hour"`
+	Days    []string `xml:"channel>skipDays>day"`
+}
+
+func (v *validator) atomFeed(feed *AtomFeed) {
+	v.required("/feed/id", feed.ID)
+	v.absolute("/feed/id", feed.ID, SeverityError)
+	v.required("/feed/title", feed.Title.Body+feed.Title.InnerXML)
+	v.required("/feed/updated", feed.Updated)
+	v.rfc3339("/feed/updated", feed.Updated)
+	v.atomLinks("/feed", feed.Links)
+
+	ids := make(map[string]int)
+	for i, entry := range feed.Entries {
+		path := fmt.Sprintf("/feed/entry[%d]", i+1)
+
+		v.required(path+"/id", entry.ID)
+		v.absolute(path+"/id", entry.ID, SeverityError)
+		v.required(path+"/title", entry.Title.Body+entry.Title.InnerXML)
+		v.required(path+"/updated", entry.Updated)
+		v.rfc3339(path+"/updated", entry.Updated)
+		v.rfc3339(path+"/published", entry.Published)
+		v.atomLinks(path, entry.Links)
+
+		if first, ok := ids[entry.ID]; ok && len(entry.ID) > 0 {
+			v.errorf(path+"/id", "duplicate id %q, first used by entry %d", entry.ID, first)
+		} else {
+			ids[entry.ID] = i + 1
+		}
+	}
+}
+
+func (v *validator) atomLinks(path string, links []AtomLink) {
+	for i, link := range links {
+		path := fmt.Sprintf("%s/link[%d]", path, i+1)
+
+		v.required(path+"/@href", link.Href)
+		v.absolute(path+"/@href", link.Href, SeverityWarning)
+		if len(link.Length) > 0 {
+			v.length(path+"/@length", link.Length)
+		}
+	}
+}
+
+func (v *validator) rssFeed(feed *RssFeed) {
+	v.required("/rss/channel/title", feed.Title)
+	v.required("/rss/channel/link", feed.Link)
+	v.absolute("/rss/channel/link", feed.Link, SeverityError)
+	v.required("/rss/channel/description", feed.Description)
+	v.rfc822("/rss/channel/pubDate", feed.PubDate)
+	v.rfc822("/rss/channel/lastBuildDate", feed.LastBuildDate)
+	v.absolute("/rss/channel/image/url", feed.Image.URL, SeverityError)
+
+	guids := make(map[string]int)
+	for i, item := range feed.Items {
+		path := fmt.Sprintf("/rss/channel/item[%d]", i+1)
+
+		if len(item.Title) == 0 && len(item.Description) == 0 {
+			v.errorf(path, "either title or description is required")
+		}
+		v.absolute(path+"/link", item.Link, SeverityError)
+		v.absolute(path+"/comments", item.Comments, SeverityError)
+		v.rfc822(path+"/pubDate", item.PubDate)
+
+		if len(item.Enclosure.URL+item.Enclosure.Length+item.Enclosure.Type) > 0 {
+			v.required(path+"/enclosure/@url", item.Enclosure.URL)
+			v.absolute(path+"/enclosure/@url", item.Enclosure.URL, SeverityError)
+			v.required(path+"/enclosure/@length", item.Enclosure.Length)
+			v.length(path+"/enclosure/@length", item.Enclosure.Length)
+			v.required(path+"/enclosure/@type", item.Enclosure.Type)
+		}
+
+		guid := item.GUID.Value
+		if first, ok := guids[guid]; ok && len(guid) > 0 {
+			v.errorf(path+"/guid", "duplicate guid %q, first used by item %d", guid, first)
+		} else {
+			guids[guid] = i + 1
+		}
+	}
+}
+
+func (v *validator) rssSkip(skip *rssSkip) {
+	for i, hour := range skip.Hours {
+		n, err := strconv.Atoi(strings.TrimSpace(hour))
+		if err != nil || n < 0 || n > 23 {
+			v.errorf(fmt.Sprintf("/rss/channel/skipHours/hour[%d]", i+1),
+				"invalid hour %q, must be between 0 and 23", hour)
+		}
+	}
+
+	for i, day := range skip.Days {
+		if !contains(weekdays, strings.TrimSpace(day)) {
+			v.errorf(fmt.Sprintf("/rss/channel/skipDays/day[%d]", i+1),
+				"invalid day %q, must be a weekday (e.g. Monday)", day)
+		}
+	}
+}
+
+// required reports an error if a required element is missing.
+func (v *validator) required(path, value string) {
+	if len(strings.TrimSpace(value)) == 0 {
+		v.errorf(path, "missing required element")
+	}
+}
+
+// absolute reports an issue with the given severity if the value is
+// present but not an absolute URI.
+func (v *validator) absolute(path, value string, severity Severity) {
+	if len(value) == 0 {
+		return
+	}
+
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || !u.IsAbs() {
+		v.report(severity, path, "%q is not an absolute URI", value)
+	}
+}
+
+// rfc3339 reports an error if the value is present but not an RFC 3339
+// date.
+func (v *validator) rfc3339(path, value string) {
+	if len(value) == 0 {
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, strings.TrimSpace(value)); err != nil {
+		v.errorf(path, "%q is not an RFC 3339 date", value)
+	}
+}
+
+// rfc822 reports an error if the value is present but not an RFC 822
+// date.
+func (v *validator) rfc822(path, value string) {
+	if len(value) == 0 {
+		return
+	}
+
+	date := strings.TrimSpace(value)
+	if strings.HasSuffix(date, " UT") || strings.HasSuffix(date, " Z") {
+		date = date[:strings.LastIndex(date, " ")] + " +0000"
+	}
+
+	for _, format := range rfc822Formats {
+		if _, err := time.Parse(format, date); err == nil {
+			return
+		}
+	}
+
+	v.errorf(path, "%q is not an RFC 822 date", value)
+}
+
+// length reports an error if the value is not a valid length in bytes.
+func (v *validator) length(path, value string) {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if len(value) > 0 && (err != nil || n < 0) {
+		v.errorf(path, "invalid length %q, must be a non-negative integer", value)
+	}
+}
+
+func (v *validator) errorf(path, format string, a ...interface{}) {
+	v.report(SeverityError, path, format, a...)
+}
+
+// report records an issue with the given severity for the given path.
+func (v *validator) report(severity Severity, path, format string, a ...interface{}) {
+	v.issues = append(v.issues, Issue{severity, path, fmt.Sprintf(format, a...), v.s.position(path)})
+}
+
+// contains reports whether the slice contains the given string.
+func contains(slice []string, s string) bool {
+	for _, e := range slice {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
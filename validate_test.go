@@ -0,0 +1,116 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package feedparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+type validatepair struct {
+	Data   string
+	Issues []string
+}
+
+func TestValidate(t *testing.T) {
+	tests := []validatepair{
+		{testAtom, []string{
+			"/feed/entry[1]/id",
+			"/feed/entry[2]/id",
+		}},
+		{testRss, nil},
+		{`<feed xmlns="http://www.w3.org/2005/Atom">
+			<updated>05 Aug 2015</updated>
+			<link href="/relative"/>
+			<entry>
+				<id>urn:x</id>
+				<title>T</title>
+				<updated>2015-08-05T18:30:02Z</updated>
+				<link rel="enclosure" href="http://example.org/a.mp3" length="-1"/>
+			</entry>
+			<entry>
+				<id>urn:x</id>
+				<title>T</title>
+				<updated>2015-08-05T18:30:02Z</updated>
+			</entry>
+		</feed>`, []string{
+			"/feed/id",
+			"/feed/title",
+			"/feed/updated",
+			"/feed/link[1]/@href",
+			"/feed/entry[1]/link[1]/@length",
+			"/feed/entry[2]/id",
+		}},
+		{`<feed xmlns="http://www.w3.org/2005/Atom">
+			<id>urn:feed</id>
+			<title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><b>Feed</b></div></title>
+			<updated>2015-08-05T18:30:02Z</updated>
+			<author><name>A</name></author>
+			<entry>
+				<id>urn:entry</id>
+				<title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><i>Entry</i></div></title>
+				<updated>2015-08-05T18:30:02Z</updated>
+			</entry>
+		</feed>`, nil},
+		{`<rss version="2.0"><channel>
+			<link>example.org</link>
+			<description>D</description>
+			<pubDate>2015-08-05</pubDate>
+			<skipHours><hour>0</hour><hour>24</hour></skipHours>
+			<skipDays><day>Sunday</day><day>Caturday</day></skipDays>
+			<item><guid>1</guid><pubDate>Wed, 05 Aug 2015 10:00 UT</pubDate></item>
+			<item>
+				<title>T</title>
+				<guid>1</guid>
+				<enclosure url="/a.mp3" length="large"/>
+			</item>
+		</channel></rss>`, []string{
+			"/rss/channel/skipHours/hour[2]",
+			"/rss/channel/skipDays/day[2]",
+			"/rss/channel/title",
+			"/rss/channel/link",
+			"/rss/channel/pubDate",
+			"/rss/channel/item[1]",
+			"/rss/channel/item[2]/enclosure/@url",
+			"/rss/channel/item[2]/enclosure/@length",
+			"/rss/channel/item[2]/enclosure/@type",
+			"/rss/channel/item[2]/guid",
+		}},
+	}
+
+	for _, test := range tests {
+		var paths []string
+		for _, issue := range Validate([]byte(test.Data)) {
+			paths = append(paths, issue.Path)
+		}
+
+		if !reflect.DeepEqual(paths, test.Issues) {
+			t.Fatalf("Expected %q - got %q", test.Issues, paths)
+		}
+	}
+}
+
+func TestValidatePosition(t *testing.T) {
+	data := "<rss version=\"2.0\"><channel>\n<title>T</title>\n<link>http://example.org</link>\n" +
+		"<description>D</description>\n<item>\n<title>T</title>\n<pubDate>yesterday</pubDate>\n</item>\n</channel></rss>"
+
+	issues := Validate([]byte(data))
+	if len(issues) != 1 {
+		t.Fatalf("Expected one issue - got %v", issues)
+	}
+
+	if issues[0].Position.Line != 7 {
+		t.Fatalf("Expected line 7 - got %v", issues[0])
+	}
+}
@@ -0,0 +1,319 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package websub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxSecretLength is the maximum length of secrets in bytes permitted by
+// the specification.
+const maxSecretLength = 200
+
+// defaultLease is the lease duration used if a Hub has none configured.
+const defaultLease = 10 * 24 * time.Hour
+
+// ErrVerification is returned if a subscriber doesn't confirm an intent.
+var ErrVerification = errors.New("websub: subscriber didn't confirm intent")
+
+// hubSubscription represents a subscription of a callback at a Hub.
+type hubSubscription struct {
+	secret  string
+	expires time.Time
+}
+
+// Hub is a minimal WebSub hub which can be embedded in applications
+// publishing feeds. It accepts subscriptions, verifies the intent of
+// subscribers and distributes topics to subscribers once a publisher
+// notified the hub about an update, see Publish. All of this happens
+// synchronously while handling the request, which keeps the hub simple
+// but makes it only suitable for a small number of subscribers. A Hub
+// is safe for concurrent use by multiple goroutines.
+//
+// Publish requests are not authenticated, anyone can cause the hub to
+// fetch and distribute a topic it serves. Hence, a Hub only serves the
+// topics permitted by Allow, which must refer to the publisher's own
+// feeds, since fetched topics are delivered to arbitrary callbacks.
+type Hub struct {
+	// HTTP client used for requests to subscribers and topics (optional).
+	Client *http.Client
+
+	// Function reporting whether the given topic is served by the hub,
+	// no topics are served if it is nil (required).
+	Allow func(topic string) bool
+
+	// Lease duration of subscriptions not requesting one, defaults to
+	// ten days (optional).
+	Lease time.Duration
+
+	// Maximum lease duration of subscriptions, unlimited if zero
+	// (optional).
+	MaxLease time.Duration
+
+	// Maximum size of fetched topics in bytes, defaults to 10 MiB
+	// (optional).
+	MaxSize int64
+
+	mu   sync.Mutex
+	subs map[string]map[string]*hubSubscription
+}
+
+// ServeHTTP handles subscription requests of subscribers and publish
+// requests of publishers.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch mode := r.PostForm.Get("hub.mode"); mode {
+	case "subscribe", "unsubscribe":
+		h.subscribe(w, r, mode)
+	case "publish":
+		h.publish(w, r)
+	default:
+		http.Error(w, "unknown hub.mode", http.StatusBadRequest)
+	}
+}
+
+// subscribe handles a subscription request after verifying the intent
+// of the subscriber.
+func (h *Hub) subscribe(w http.ResponseWriter, r *http.Request, mode string) {
+	topic, callback := r.PostForm.Get("hub.topic"), r.PostForm.Get("hub.callback")
+	if !isHTTP(callback) {
+		http.Error(w, "invalid hub.callback", http.StatusBadRequest)
+		return
+	} else if !h.allowed(topic) {
+		http.Error(w, "invalid hub.topic", http.StatusBadRequest)
+		return
+	}
+
+	secret := r.PostForm.Get("hub.secret")
+	if len(secret) >= maxSecretLength {
+		http.Error(w, "hub.secret too long", http.StatusBadRequest)
+		return
+	}
+
+	lease := h.lease(r.PostForm.Get("hub.lease_seconds"))
+	if err := h.verify(r.Context(), mode, topic, callback, lease); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if mode == "unsubscribe" {
+		delete(h.subs[topic], callback)
+		if len(h.subs[topic]) == 0 {
+			delete(h.subs, topic)
+		}
+	} else {
+		if h.subs == nil {
+			h.subs = make(map[string]map[string]*hubSubscription)
+		}
+		if h.subs[topic] == nil {
+			h.subs[topic] = make(map[string]*hubSubscription)
+		}
+		h.subs[topic][callback] = &hubSubscription{secret, time.Now().Add(lease)}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// verify verifies the intent of the subscriber by requesting it to echo
+// a random challenge.
+func (h *Hub) verify(ctx context.Context, mode, topic, callback string, lease time.Duration) error {
+	challenge, err := randomString(16)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(callback)
+	if err != nil {
+		return err
+	}
+
+	query := u.Query()
+	query.Set("hub.mode", mode)
+	query.Set("hub.topic", topic)
+	query.Set("hub.challenge", challenge)
+	if mode == "subscribe" {
+		query.Set("hub.lease_seconds", strconv.Itoa(int(lease/time.Second)))
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client(h.Client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, int64(len(challenge))+1))
+	if err != nil {
+		return err
+	} else if resp.StatusCode < 200 || resp.StatusCode > 299 || string(body) != challenge {
+		return ErrVerification
+	}
+
+	return nil
+}
+
+// publish handles a publish request by distributing the updated topics.
+func (h *Hub) publish(w http.ResponseWriter, r *http.Request) {
+	topics := append(r.PostForm["hub.url"], r.PostForm["hub.topic"]...)
+	if len(topics) == 0 {
+		http.Error(w, "missing hub.url", http.StatusBadRequest)
+		return
+	}
+
+	for _, topic := range topics {
+		if !h.allowed(topic) {
+			http.Error(w, "invalid hub.url", http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, topic := range topics {
+		if err := h.Distribute(r.Context(), topic); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Distribute fetches the given topic and distributes it to all of its
+// subscribers. Expired subscriptions are removed, failed deliveries are
+// not retried.
+func (h *Hub) Distribute(ctx context.Context, topic string) error {
+	subs := h.subscriptions(topic)
+	if len(subs) == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", topic, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client(h.Client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("websub: fetching topic failed with status %d", resp.StatusCode)
+	}
+
+	maxSize := h.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return err
+	} else if int64(len(content)) > maxSize {
+		return fmt.Errorf("websub: topic exceeds maximum size of %d bytes", maxSize)
+	}
+
+	for callback, sub := range subs {
+		req, err := http.NewRequestWithContext(ctx, "POST", callback, bytes.NewReader(content))
+		if err != nil {
+			continue
+		}
+
+		req.Header.Set("Content-Type", resp.Header.Get("Content-Type"))
+		req.Header.Set("Link", fmt.Sprintf("<%s>; rel=\"self\"", topic))
+		if len(sub.secret) > 0 {
+			req.Header.Set(signatureHeader, sign("sha256", sub.secret, content))
+		}
+
+		if resp, err := client(h.Client).Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	return nil
+}
+
+// subscriptions returns the active subscriptions of the given topic by
+// callback and removes expired ones.
+func (h *Hub) subscriptions(topic string) map[string]hubSubscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	subs := make(map[string]hubSubscription)
+	for callback, sub := range h.subs[topic] {
+		if now.After(sub.expires) {
+			delete(h.subs[topic], callback)
+			continue
+		}
+		subs[callback] = *sub
+	}
+
+	return subs
+}
+
+// lease returns the lease duration for the given requested lease in
+// seconds.
+func (h *Hub) lease(seconds string) time.Duration {
+	lease := h.Lease
+	if lease <= 0 {
+		lease = defaultLease
+	}
+
+	if n, err := strconv.Atoi(seconds); err == nil && n > 0 {
+		lease = time.Duration(n) * time.Second
+	}
+	if h.MaxLease > 0 && lease > h.MaxLease {
+		lease = h.MaxLease
+	}
+
+	return lease
+}
+
+// allowed reports whether the hub serves the given topic.
+func (h *Hub) allowed(topic string) bool {
+	return h.Allow != nil && isHTTP(topic) && h.Allow(topic)
+}
+
+// isHTTP reports whether the given string is an absolute HTTP URL.
+func isHTTP(rawurl string) bool {
+	u, err := url.Parse(rawurl)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0
+}
@@ -0,0 +1,221 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package websub
+
+import (
+	"context"
+	"fmt"
+	"github.com/nmeum/go-feedparser"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTopic returns a server serving a feed which advertises the given
+// hub, the returned function changes the title of its item.
+func newTopic(hub string) (*httptest.Server, func(string)) {
+	var mu sync.Mutex
+	title := "First"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprintf(w, `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>
+			<title>Test</title>
+			<atom:link rel="self" href="http://%s/"/>
+			<atom:link rel="hub" href="%s"/>
+			<item>
+				<title>%s</title>
+				<pubDate>Mon, 03 Aug 2015 10:00:00 GMT</pubDate>
+			</item>
+		</channel></rss>`, r.Host, hub, title)
+	}))
+
+	return server, func(t string) {
+		mu.Lock()
+		title = t
+		mu.Unlock()
+	}
+}
+
+func TestHub(t *testing.T) {
+	hub := &Hub{MaxLease: time.Hour}
+	hubServer := httptest.NewServer(hub)
+	defer hubServer.Close()
+
+	topic, setTitle := newTopic(hubServer.URL)
+	defer topic.Close()
+	hub.Allow = func(t string) bool { return t == topic.URL+"/" }
+
+	var mu sync.Mutex
+	var titles []string
+	subscriber := &Subscriber{
+		Lease: 24 * time.Hour,
+		Deliver: func(sub *Subscription, f feedparser.Feed) {
+			mu.Lock()
+			titles = append(titles, f.Items[0].Title)
+			mu.Unlock()
+		},
+	}
+	callbacks := httptest.NewServer(subscriber)
+	defer callbacks.Close()
+	subscriber.CallbackURL = callbacks.URL
+
+	result, err := (&feedparser.Fetcher{}).Fetch(context.Background(), topic.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := result.Feed
+
+	sub, err := subscriber.SubscribeFeed(context.Background(), feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sub.Active() {
+		t.Fatal("Expected subscription to be active")
+	}
+	if d := time.Until(sub.Expires()); d < 59*time.Minute || d > time.Hour {
+		t.Fatalf("Expected lease to be limited to an hour - got %v", d)
+	}
+
+	setTitle("Second")
+	if err := PublishFeed(context.Background(), nil, feed); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := subscriber.Unsubscribe(context.Background(), sub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.Active() {
+		t.Fatal("Expected subscription to be inactive")
+	}
+
+	setTitle("Third")
+	if err := PublishFeed(context.Background(), nil, feed); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(titles) != 1 || titles[0] != "Second" {
+		t.Fatalf("Expected only the second item to be delivered - got %q", titles)
+	}
+}
+
+func TestHubSignature(t *testing.T) {
+	hub := &Hub{}
+	hubServer := httptest.NewServer(hub)
+	defer hubServer.Close()
+
+	topic, _ := newTopic(hubServer.URL)
+	defer topic.Close()
+	hub.Allow = func(t string) bool { return t == topic.URL+"/" }
+
+	var signature, content string
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			io.WriteString(w, r.URL.Query().Get("hub.challenge"))
+			return
+		}
+
+		var b strings.Builder
+		io.Copy(&b, r.Body)
+		signature, content = r.Header.Get(signatureHeader), b.String()
+	}))
+	defer callback.Close()
+
+	form := map[string][]string{
+		"hub.mode":     {"subscribe"},
+		"hub.topic":    {topic.URL + "/"},
+		"hub.callback": {callback.URL},
+		"hub.secret":   {"secret"},
+	}
+	if err := post(context.Background(), nil, hubServer.URL, form); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Publish(context.Background(), nil, hubServer.URL, topic.URL+"/"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(content) == 0 || signature != sign("sha256", "secret", []byte(content)) {
+		t.Fatalf("Expected signed content - got %q with signature %q", content, signature)
+	}
+}
+
+type hubpair struct {
+	form  map[string][]string
+	allow func(string) bool
+	code  int
+}
+
+func TestHubErrors(t *testing.T) {
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "wrong challenge")
+	}))
+	defer callback.Close()
+
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.URL.Query().Get("hub.challenge"))
+	}))
+	defer echo.Close()
+
+	onlyFeed := func(topic string) bool { return topic == "http://example.org/feed" }
+	allowAll := func(topic string) bool { return true }
+	subscribe := func(topic, callback string) map[string][]string {
+		return map[string][]string{
+			"hub.mode":     {"subscribe"},
+			"hub.topic":    {topic},
+			"hub.callback": {callback},
+		}
+	}
+
+	tests := []hubpair{
+		{map[string][]string{}, nil, http.StatusBadRequest},
+		{map[string][]string{"hub.mode": {"publish"}}, nil, http.StatusBadRequest},
+		{map[string][]string{"hub.mode": {"publish"}, "hub.url": {"/feed"}}, allowAll, http.StatusBadRequest},
+		{map[string][]string{"hub.mode": {"publish"}, "hub.url": {"http://example.org/feed"}}, nil, http.StatusBadRequest},
+		{map[string][]string{"hub.mode": {"publish"}, "hub.url": {"http://127.0.0.1/"}}, nil, http.StatusBadRequest},
+		{map[string][]string{"hub.mode": {"publish"}, "hub.url": {"http://example.org/rss"}}, onlyFeed, http.StatusBadRequest},
+		{map[string][]string{"hub.mode": {"publish"}, "hub.url": {"http://example.org/feed"}}, onlyFeed, http.StatusAccepted},
+		{subscribe("http://example.org/feed", "ftp://example.org/"), allowAll, http.StatusBadRequest},
+		{subscribe("http://169.254.169.254/latest/meta-data/", echo.URL), nil, http.StatusBadRequest},
+		{subscribe("http://example.org/feed", echo.URL), onlyFeed, http.StatusAccepted},
+		{subscribe("http://example.org/rss", callback.URL), onlyFeed, http.StatusBadRequest},
+		{subscribe("http://example.org/feed", callback.URL), onlyFeed, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		hub := httptest.NewServer(&Hub{Allow: test.allow})
+		err := post(context.Background(), nil, hub.URL, test.form)
+		hub.Close()
+
+		code := http.StatusAccepted
+		if rerr, ok := err.(*RequestError); ok {
+			code = rerr.StatusCode
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		if code != test.code {
+			t.Fatalf("Expected %d for %v - got %d (%v)", test.code, test.form, code, err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package websub
+
+import (
+	"context"
+	"github.com/nmeum/go-feedparser"
+	"net/http"
+	"net/url"
+)
+
+// Publish notifies the given hub that the given topics were updated. The
+// hub then fetches the topics and distributes them to its subscribers.
+func Publish(ctx context.Context, c *http.Client, hub string, topics ...string) error {
+	form := url.Values{
+		"hub.mode": {"publish"},
+		"hub.url":  topics,
+	}
+
+	return post(ctx, c, hub, form)
+}
+
+// PublishFeed notifies all hubs advertised by the given feed that the
+// feed was updated, see Discover. Hubs are notified in order and the
+// first error is returned.
+func PublishFeed(ctx context.Context, c *http.Client, f feedparser.Feed) error {
+	hubs, topic := Discover(f)
+	if len(hubs) == 0 || len(topic) == 0 {
+		return ErrNoHub
+	}
+
+	for _, hub := range hubs {
+		if err := Publish(ctx, c, hub, topic); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package websub
+
+import (
+	"context"
+	"github.com/nmeum/go-feedparser"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	var forms []url.Values
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		forms = append(forms, r.PostForm)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hub.Close()
+
+	feed := feedparser.Feed{
+		Links: []feedparser.Link{
+			{Href: "http://example.org/", Rel: "alternate"},
+			{Href: "http://example.org/rss.xml", Rel: "self"},
+			{Href: hub.URL, Rel: "hub"},
+			{Href: hub.URL + "/second", Rel: "hub"},
+		},
+	}
+
+	if err := PublishFeed(context.Background(), nil, feed); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := url.Values{"hub.mode": {"publish"}, "hub.url": {"http://example.org/rss.xml"}}
+	if len(forms) != 2 || !reflect.DeepEqual(forms[0], expected) || !reflect.DeepEqual(forms[1], expected) {
+		t.Fatalf("Expected two requests with %v - got %v", expected, forms)
+	}
+
+	if err := PublishFeed(context.Background(), nil, feedparser.Feed{}); err != ErrNoHub {
+		t.Fatalf("Expected %v - got %v", ErrNoHub, err)
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unknown topic", http.StatusBadRequest)
+	}))
+	defer hub.Close()
+
+	err := Publish(context.Background(), nil, hub.URL, "http://example.org/rss.xml")
+	if rerr, ok := err.(*RequestError); !ok || rerr.StatusCode != http.StatusBadRequest || rerr.Message != "unknown topic" {
+		t.Fatalf("Expected *RequestError - got %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package websub
+
+import (
+	"bytes"
+	"context"
+	"github.com/nmeum/go-feedparser"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Subscription represents a subscription to a topic at a hub.
+type Subscription struct {
+	// URL of the hub.
+	Hub string
+
+	// URL of the topic, usually the URL of a feed.
+	Topic string
+
+	// URL the hub delivers content to.
+	Callback string
+
+	// Secret used by the hub to sign delivered content.
+	Secret string
+
+	mu          sync.Mutex
+	active      bool
+	unsubscribe bool
+	expires     time.Time
+	reason      string
+}
+
+// Active reports whether the hub verified the subscription.
+func (s *Subscription) Active() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.active
+}
+
+// Expires returns the time the subscription expires as requested by the
+// hub, zero if the subscription isn't active or doesn't expire.
+func (s *Subscription) Expires() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.expires
+}
+
+// Denied returns the reason given by the hub for denying the
+// subscription or an empty string if it wasn't denied.
+func (s *Subscription) Denied() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reason
+}
+
+// Subscriber subscribes to topics at hubs and receives the content they
+// distribute. It is an http.Handler which must be reachable by the hubs
+// at CallbackURL, every subscription uses a distinct path below it. A
+// Subscriber is safe for concurrent use by multiple goroutines.
+type Subscriber struct {
+	// Base URL of the callbacks of all subscriptions (required).
+	CallbackURL string
+
+	// Function called with each feed delivered by a hub (required).
+	Deliver func(sub *Subscription, f feedparser.Feed)
+
+	// HTTP client used to send requests to hubs (optional).
+	Client *http.Client
+
+	// Parser used to parse delivered content. Its MaxSize limits the
+	// size of delivered content, which defaults to 10 MiB (optional).
+	Parser *feedparser.Parser
+
+	// Requested lease duration of subscriptions, the hub decides on the
+	// actual duration (optional).
+	Lease time.Duration
+
+	mu   sync.Mutex
+	subs map[string]*Subscription
+}
+
+// SubscribeFeed subscribes to the given feed at the first hub it
+// advertises, see Discover.
+func (s *Subscriber) SubscribeFeed(ctx context.Context, f feedparser.Feed) (*Subscription, error) {
+	hubs, topic := Discover(f)
+	if len(hubs) == 0 || len(topic) == 0 {
+		return nil, ErrNoHub
+	}
+
+	return s.Subscribe(ctx, hubs[0], topic)
+}
+
+// Subscribe requests a subscription to the given topic at the given hub.
+// The subscription becomes active once the hub verified it, which may
+// happen asynchronously.
+func (s *Subscriber) Subscribe(ctx context.Context, hub, topic string) (*Subscription, error) {
+	id, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		Hub:      hub,
+		Topic:    topic,
+		Callback: strings.TrimSuffix(s.CallbackURL, "/") + "/" + id,
+		Secret:   secret,
+	}
+
+	s.mu.Lock()
+	if s.subs == nil {
+		s.subs = make(map[string]*Subscription)
+	}
+	s.subs[id] = sub
+	s.mu.Unlock()
+
+	form := url.Values{
+		"hub.mode":     {"subscribe"},
+		"hub.topic":    {topic},
+		"hub.callback": {sub.Callback},
+		"hub.secret":   {secret},
+	}
+	if s.Lease > 0 {
+		form.Set("hub.lease_seconds", strconv.Itoa(int(s.Lease/time.Second)))
+	}
+
+	if err := post(ctx, s.Client, hub, form); err != nil {
+		s.mu.Lock()
+		delete(s.subs, id)
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// Unsubscribe requests the hub to cancel the given subscription. The
+// subscription is removed once the hub verified the request.
+func (s *Subscriber) Unsubscribe(ctx context.Context, sub *Subscription) error {
+	sub.mu.Lock()
+	sub.unsubscribe = true
+	sub.mu.Unlock()
+
+	form := url.Values{
+		"hub.mode":     {"unsubscribe"},
+		"hub.topic":    {sub.Topic},
+		"hub.callback": {sub.Callback},
+	}
+
+	err := post(ctx, s.Client, sub.Hub, form)
+	if err != nil {
+		sub.mu.Lock()
+		sub.unsubscribe = false
+		sub.mu.Unlock()
+	}
+
+	return err
+}
+
+// ServeHTTP handles verification requests and content distribution
+// requests of hubs.
+func (s *Subscriber) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := path.Base(r.URL.Path)
+
+	s.mu.Lock()
+	sub, ok := s.subs[id]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		s.verify(w, r, id, sub)
+	case "POST":
+		s.distribute(w, r, sub)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// verify handles a verification request of a hub, which either confirms
+// an intent or reports a denied subscription.
+func (s *Subscriber) verify(w http.ResponseWriter, r *http.Request, id string, sub *Subscription) {
+	query := r.URL.Query()
+	if query.Get("hub.topic") != sub.Topic {
+		http.NotFound(w, r)
+		return
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	switch query.Get("hub.mode") {
+	case "subscribe":
+		if sub.unsubscribe {
+			http.NotFound(w, r)
+			return
+		}
+
+		sub.active = true
+		sub.expires = time.Time{}
+		if lease, err := strconv.Atoi(query.Get("hub.lease_seconds")); err == nil && lease > 0 {
+			sub.expires = time.Now().Add(time.Duration(lease) * time.Second)
+		}
+	case "unsubscribe":
+		if !sub.unsubscribe {
+			http.NotFound(w, r)
+			return
+		}
+
+		sub.active = false
+		s.remove(id)
+	case "denied":
+		sub.active = false
+		sub.reason = query.Get("hub.reason")
+		if len(sub.reason) == 0 {
+			sub.reason = "denied"
+		}
+		s.remove(id)
+
+		w.WriteHeader(http.StatusOK)
+		return
+	default:
+		http.Error(w, "unknown hub.mode", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, query.Get("hub.challenge"))
+}
+
+// distribute handles content distributed by a hub. Content with an
+// invalid signature is acknowledged but ignored as mandated by the
+// specification.
+func (s *Subscriber) distribute(w http.ResponseWriter, r *http.Request, sub *Subscription) {
+	if !sub.Active() {
+		http.NotFound(w, r)
+		return
+	}
+
+	parser := s.Parser
+	if parser == nil {
+		parser = feedparser.DefaultParser
+	}
+
+	maxSize := parser.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if int64(len(content)) > maxSize {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if !verify(r.Header.Get(signatureHeader), sub.Secret, content) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	f, err := parser.ParseContentType(r.Context(), bytes.NewReader(content), r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	s.Deliver(sub, f)
+}
+
+// remove removes the subscription with the given ID.
+func (s *Subscriber) remove(id string) {
+	s.mu.Lock()
+	delete(s.subs, id)
+	s.mu.Unlock()
+}
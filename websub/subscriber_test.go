@@ -0,0 +1,264 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package websub
+
+import (
+	"bytes"
+	"context"
+	"github.com/nmeum/go-feedparser"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testFeed = `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>
+	<title>Test</title>
+	<link>http://example.org/</link>
+	<atom:link rel="self" href="http://example.org/rss.xml"/>
+	<atom:link rel="hub" href="%s"/>
+	<item>
+		<title>First</title>
+		<guid>1</guid>
+		<pubDate>Mon, 03 Aug 2015 10:00:00 GMT</pubDate>
+	</item>
+</channel></rss>`
+
+// fakeHub is a hub which verifies intents synchronously and records the
+// subscriptions it accepted.
+type fakeHub struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	callbacks map[string]string
+	secrets   map[string]string
+}
+
+func (h *fakeHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	mode, topic, callback := r.Form.Get("hub.mode"), r.Form.Get("hub.topic"), r.Form.Get("hub.callback")
+
+	challenge := "challenge-" + mode
+	u, _ := url.Parse(callback)
+	query := u.Query()
+	query.Set("hub.mode", mode)
+	query.Set("hub.topic", topic)
+	query.Set("hub.challenge", challenge)
+	query.Set("hub.lease_seconds", "3600")
+	u.RawQuery = query.Encode()
+
+	resp, err := http.Get(u.String())
+	if err != nil {
+		h.t.Error(err)
+		return
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != challenge {
+		http.Error(w, "verification failed", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if mode == "subscribe" {
+		h.callbacks[topic] = callback
+		h.secrets[topic] = r.Form.Get("hub.secret")
+	} else {
+		delete(h.callbacks, topic)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// publish distributes the given content to the subscriber of the topic
+// and returns the status code of the response.
+func (h *fakeHub) publish(topic, content, signature string) int {
+	h.mu.Lock()
+	callback, secret := h.callbacks[topic], h.secrets[topic]
+	h.mu.Unlock()
+
+	if len(callback) == 0 {
+		return 0
+	}
+	if len(signature) == 0 {
+		signature = sign("sha256", secret, []byte(content))
+	}
+
+	req, _ := http.NewRequest("POST", callback, strings.NewReader(content))
+	req.Header.Set("Content-Type", "application/rss+xml")
+	req.Header.Set(signatureHeader, signature)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func TestDiscover(t *testing.T) {
+	feed, err := feedparser.Parse(strings.NewReader(strings.Replace(testFeed, "%s", "http://hub.example.org/", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hubs, topic := Discover(feed)
+	if !reflect.DeepEqual(hubs, []string{"http://hub.example.org/"}) {
+		t.Fatalf("Expected %q - got %q", []string{"http://hub.example.org/"}, hubs)
+	}
+	if topic != "http://example.org/rss.xml" {
+		t.Fatalf("Expected %q - got %q", "http://example.org/rss.xml", topic)
+	}
+
+	if _, err := (&Subscriber{}).SubscribeFeed(context.Background(), feedparser.Feed{}); err != ErrNoHub {
+		t.Fatalf("Expected %v - got %v", ErrNoHub, err)
+	}
+}
+
+func TestSubscriber(t *testing.T) {
+	var delivered []feedparser.Feed
+	subscriber := &Subscriber{
+		Deliver: func(sub *Subscription, f feedparser.Feed) {
+			delivered = append(delivered, f)
+		},
+	}
+
+	callbacks := httptest.NewServer(subscriber)
+	defer callbacks.Close()
+	subscriber.CallbackURL = callbacks.URL + "/websub/"
+
+	hub := &fakeHub{t: t, callbacks: make(map[string]string), secrets: make(map[string]string)}
+	hubServer := httptest.NewServer(hub)
+	defer hubServer.Close()
+
+	content := strings.Replace(testFeed, "%s", hubServer.URL, 1)
+	feed, err := feedparser.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := subscriber.SubscribeFeed(context.Background(), feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !sub.Active() {
+		t.Fatal("Expected subscription to be active")
+	}
+	if d := time.Until(sub.Expires()); d < 59*time.Minute || d > time.Hour {
+		t.Fatalf("Expected subscription to expire in an hour - got %v", d)
+	}
+
+	topic := "http://example.org/rss.xml"
+	if code := hub.publish(topic, content, ""); code != http.StatusAccepted {
+		t.Fatalf("Expected %d - got %d", http.StatusAccepted, code)
+	}
+	if len(delivered) != 1 || delivered[0].Items[0].Title != "First" {
+		t.Fatalf("Expected feed to be delivered - got %v", delivered)
+	}
+
+	if code := hub.publish(topic, content, "sha256=0000"); code != http.StatusAccepted {
+		t.Fatalf("Expected %d - got %d", http.StatusAccepted, code)
+	}
+	if code := hub.publish(topic, content, "md5=0000"); code != http.StatusAccepted {
+		t.Fatalf("Expected %d - got %d", http.StatusAccepted, code)
+	}
+	if len(delivered) != 1 {
+		t.Fatalf("Expected content with invalid signature to be ignored - got %d feeds", len(delivered))
+	}
+
+	large := strings.Repeat(" ", defaultMaxSize) + content
+	if code := hub.publish(topic, large, ""); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected %d - got %d", http.StatusRequestEntityTooLarge, code)
+	}
+
+	resp, err := http.Get(sub.Callback + "?hub.mode=unsubscribe&hub.topic=" + url.QueryEscape(topic) + "&hub.challenge=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected unrequested unsubscription to be rejected - got %d", resp.StatusCode)
+	}
+
+	if err := subscriber.Unsubscribe(context.Background(), sub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.Active() {
+		t.Fatal("Expected subscription to be inactive")
+	}
+
+	resp, err = http.Post(sub.Callback, "application/rss+xml", bytes.NewReader([]byte(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected %d - got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestSubscriberDenied(t *testing.T) {
+	subscriber := &Subscriber{}
+	callbacks := httptest.NewServer(subscriber)
+	defer callbacks.Close()
+	subscriber.CallbackURL = callbacks.URL
+
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("hub.topic") == "http://example.org/forbidden" {
+			http.Error(w, "topic not allowed", http.StatusForbidden)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		go func() {
+			query := url.Values{
+				"hub.mode":   {"denied"},
+				"hub.topic":  {r.Form.Get("hub.topic")},
+				"hub.reason": {"not today"},
+			}
+			resp, err := http.Get(r.Form.Get("hub.callback") + "?" + query.Encode())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}))
+	defer hub.Close()
+
+	_, err := subscriber.Subscribe(context.Background(), hub.URL, "http://example.org/forbidden")
+	if rerr, ok := err.(*RequestError); !ok || rerr.StatusCode != http.StatusForbidden || rerr.Message != "topic not allowed" {
+		t.Fatalf("Expected *RequestError - got %v", err)
+	}
+
+	sub, err := subscriber.Subscribe(context.Background(), hub.URL, "http://example.org/rss.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100 && len(sub.Denied()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if sub.Denied() != "not today" || sub.Active() {
+		t.Fatalf("Expected subscription to be denied - got %q", sub.Denied())
+	}
+}
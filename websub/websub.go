@@ -0,0 +1,160 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package websub implements WebSub (formerly PubSubHubbub), which allows
+// receiving feed updates pushed by a hub instead of polling the feed.
+package websub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/nmeum/go-feedparser"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// signatureHeader is the header containing the signature of content
+// distributed by a hub.
+const signatureHeader = "X-Hub-Signature"
+
+// maxMessageLength is the maximum length of error messages read from
+// responses of hubs.
+const maxMessageLength = 1024
+
+// defaultMaxSize is the maximum size of content in bytes used if none
+// is configured.
+const defaultMaxSize = 10 << 20
+
+// hashes maps signature methods to their hash functions.
+var hashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// ErrNoHub is returned if a feed doesn't advertise a hub.
+var ErrNoHub = errors.New("websub: feed doesn't advertise a hub")
+
+// RequestError is returned if a hub rejects a request.
+type RequestError struct {
+	// HTTP status code of the response.
+	StatusCode int
+
+	// Body of the response, which may describe the problem.
+	Message string
+}
+
+func (e *RequestError) Error() string {
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("websub: hub responded with status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("websub: hub responded with status %d: %s", e.StatusCode, e.Message)
+}
+
+// Discover returns the URLs of the hubs advertised by the given feed and
+// the topic URL, which is the URL of the feed itself, used to subscribe
+// to the feed.
+func Discover(f feedparser.Feed) (hubs []string, topic string) {
+	for _, link := range f.Links {
+		switch link.Rel {
+		case "hub":
+			hubs = append(hubs, link.Href)
+		case "self":
+			if len(topic) == 0 {
+				topic = link.Href
+			}
+		}
+	}
+
+	return
+}
+
+// sign returns the value of the signature header for the given content
+// using the given method and secret.
+func sign(method, secret string, content []byte) string {
+	mac := hmac.New(hashes[method], []byte(secret))
+	mac.Write(content)
+
+	return method + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify reports whether the given signature header is a valid signature
+// of the given content using the given secret.
+func verify(header, secret string, content []byte) bool {
+	i := strings.Index(header, "=")
+	if i < 0 {
+		return false
+	}
+
+	method := strings.ToLower(header[:i])
+	if _, ok := hashes[method]; !ok {
+		return false
+	}
+
+	return hmac.Equal([]byte(sign(method, secret, content)), []byte(method+"="+strings.ToLower(header[i+1:])))
+}
+
+// randomString returns a random hex encoded string with the given
+// amount of random bytes.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// post sends the given form to the given hub.
+func post(ctx context.Context, c *http.Client, hub string, form url.Values) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client(c).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxMessageLength))
+		return &RequestError{resp.StatusCode, strings.TrimSpace(string(msg))}
+	}
+
+	return nil
+}
+
+// client returns the given client or http.DefaultClient if it is nil.
+func client(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+
+	return c
+}